@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+const (
+	strategyLeastLoaded = "least-loaded"
+	strategyRoundRobin  = "round-robin"
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "assign",
+		Usage: "assign unassigned issues or pull requests to members",
+		Action: func(c *cli.Context) error {
+			return action(c, &assign{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "strategy, s",
+				Value: "",
+				Usage: "assignment strategy (least-loaded or round-robin)",
+			},
+			cli.BoolFlag{
+				Name:  "update, u",
+				Usage: "if this option is set, send update request to github",
+			},
+		},
+	})
+}
+
+type assign struct {
+	Out io.Writer
+}
+
+func (a assign) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	strategy := strategyLeastLoaded
+	if conf.Assignment.Strategy != nil {
+		strategy = *conf.Assignment.Strategy
+	}
+	if str := c.String("strategy"); str != "" {
+		strategy = str
+	}
+	if strategy != strategyLeastLoaded && strategy != strategyRoundRobin {
+		return fmt.Errorf("undefined assignment strategy (%s)", strategy)
+	}
+
+	if len(conf.Assignment.Members) == 0 {
+		return errors.New("assignment members are mandatory")
+	}
+
+	issues, err := issue{}.getAllIssues(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	weights := issueWeights{
+		Priority: conf.getPriorityWeights(),
+		Size:     conf.getSizeWeights(),
+	}
+	iInfo := issue{}.createIssueInfo(issues, nil, nil, nil, weights)
+
+	assignOpes := a.createAssignOpes(issues, iInfo, conf, strategy, weights)
+
+	// output
+	fmt.Fprintf(a.Out, "# Assignment for `%s/%s` (strategy=%s)\n", *conf.User, *conf.Repo, strategy)
+	if len(assignOpes) == 0 {
+		fmt.Fprintln(a.Out, "    there are no unassigned issues")
+		return nil
+	}
+	for _, v := range assignOpes {
+		fmt.Fprintf(a.Out, "    %d: assign to `%s`\n", v.Number, conf.UserMappings.getValue(v.Assignee))
+	}
+	fmt.Fprintln(a.Out, "")
+
+	if !c.Bool("update") {
+		return nil
+	}
+
+	fmt.Fprintln(a.Out, "  Update in progress...")
+	for _, v := range assignOpes {
+		_, _, err = client.Issues.AddAssignees(context.Background(), *conf.User, *conf.Repo, v.Number, []string{v.Assignee})
+		if err != nil {
+			fmt.Fprintf(a.Out, "    %d -> assign `%s` fail (err=\"%s\")\n", v.Number, v.Assignee, err.Error())
+		} else {
+			fmt.Fprintf(a.Out, "    %d -> assign `%s` success\n", v.Number, v.Assignee)
+		}
+	}
+
+	return nil
+}
+
+func (a assign) createAssignOpes(issues []*github.Issue, iInfo issueInfo, conf *config, strategy string, weights issueWeights) []assignOpe {
+
+	issueMap := make(map[int]*github.Issue)
+	for _, v := range issues {
+		issueMap[*v.Number] = v
+	}
+
+	// current load of each member
+	loads := make(map[string]float64)
+	for k, v := range iInfo.AssigneeScores {
+		if k != noAssigneesLabel {
+			loads[k] = v
+		}
+	}
+
+	// next index of round-robin for each route (-1 is the default members)
+	nextIndex := make(map[int]int)
+
+	assignOpes := []assignOpe{}
+	for _, num := range iInfo.AssigneeIssues[noAssigneesLabel] {
+
+		routeIdx, members := a.getMembers(issueMap[num], conf)
+
+		var assignee string
+		switch strategy {
+		case strategyLeastLoaded:
+			for _, m := range members {
+				if assignee == "" || loads[m] < loads[assignee] {
+					assignee = m
+				}
+			}
+		case strategyRoundRobin:
+			assignee = members[nextIndex[routeIdx]%len(members)]
+			nextIndex[routeIdx]++
+		}
+
+		loads[assignee] += weights.getWeight(issueMap[num])
+		assignOpes = append(assignOpes, assignOpe{Number: num, Assignee: assignee})
+	}
+
+	return assignOpes
+}
+
+// getMembers returns the candidates for the issue.
+// If the issue has a label defined in routes, the members of the first matched route are returned.
+func (a assign) getMembers(issue *github.Issue, conf *config) (int, []string) {
+	for i, route := range conf.Assignment.Routes {
+		if len(route.Members) == 0 {
+			continue
+		}
+		for _, label := range issue.Labels {
+			if *label.Name == *route.LabelName {
+				return i, route.Members
+			}
+		}
+	}
+	return -1, conf.Assignment.Members
+}
+
+type assignOpe struct {
+	Number   int
+	Assignee string
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestAssignReplay(t *testing.T) {
+
+	expected := "# Assignment for `octo-org/octo-repo` (strategy=least-loaded)\n" +
+		"    3: assign to `carol.slack`\n" +
+		"    4: assign to `carol.slack`\n" +
+		"\n"
+	if out := runReplay(t, "assign"); out != expected {
+		t.Errorf("output is\n%s\nexpected\n%s", out, expected)
+	}
+
+	expected += "  Update in progress...\n" +
+		"    3 -> assign `carol` success\n" +
+		"    4 -> assign `carol` success\n"
+	if out := runReplay(t, "assign", "--update"); out != expected {
+		t.Errorf("output with update is\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestAssignCreateAssignOpes(t *testing.T) {
+
+	conf := &config{}
+	src := `{"assignment": {
+		"members": ["alice", "bob", "carol"],
+		"routes": [{"label_name": "area/frontend", "members": ["bob", "carol"]}]
+	}}`
+	if err := json.Unmarshal([]byte(src), conf); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []*github.Issue{
+		newTestIssue(1, []string{"size/L"}, []string{"alice"}),
+		newTestIssue(2, []string{"size/L"}, nil),
+		newTestIssue(3, []string{"area/frontend"}, nil),
+		newTestIssue(4, nil, nil),
+		newTestIssue(5, []string{"area/frontend"}, nil),
+	}
+	weights := issueWeights{Size: map[string]float64{"size/L": 3}}
+	iInfo := issue{}.createIssueInfo(issues, nil, nil, nil, weights)
+
+	cases := []struct {
+		strategy string
+		expected []assignOpe
+	}{
+		// the load of the assigned issue is added to the member before the next issue
+		{strategyLeastLoaded, []assignOpe{{2, "bob"}, {3, "carol"}, {4, "carol"}, {5, "carol"}}},
+		// each route has its own order
+		{strategyRoundRobin, []assignOpe{{2, "alice"}, {3, "bob"}, {4, "bob"}, {5, "carol"}}},
+	}
+
+	for _, c := range cases {
+		if opes := (assign{}).createAssignOpes(issues, iInfo, conf, c.strategy, weights); !reflect.DeepEqual(opes, c.expected) {
+			t.Errorf("%s: operations are %v, expected %v", c.strategy, opes, c.expected)
+		}
+	}
+}
@@ -1,21 +1,39 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"reflect"
 
 	"github.com/urfave/cli"
 )
 
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "config",
+		Usage: "tools for config file and label settings file",
+		Subcommands: []cli.Command{
+			configSchemaCmd(),
+			configValidateCmd(),
+			configShowCmd(),
+			configTemplateCmd(),
+		},
+	})
+}
+
+// levels are the levels of label_rule.
+var levels = []string{"High", "Middle", "Low"}
+
 // Config is a configure for GitHub.
 type config struct {
 	User      *string `json:"username"`
 	Repo      *string `json:"repository"`
 	Token     *string `json:"access_token"`
+	BaseURL   *string `json:"base_url"`
 	Message   *string `json:"message_to_assignee"`
+	Snapshot  *string `json:"snapshot_file"`
+	Retention *int    `json:"snapshot_retention_days"`
+	Template  *string `json:"report_template"`
 	LabelRule struct {
 		Priority []struct {
 			LabelName *string `json:"label_name"`
@@ -25,12 +43,119 @@ type config struct {
 			LabelName *string `json:"label_name"`
 			Level     *string `json:"level"`
 		} `json:"other"`
+		LevelWeights map[string]float64 `json:"level_weights"`
+		Size         []struct {
+			LabelName *string  `json:"label_name"`
+			Weight    *float64 `json:"weight"`
+		} `json:"size"`
 	} `json:"label_rule"`
-	UserMappingList []struct {
-		GithubName *string `json:"github_name"`
-		SlackName  *string `json:"slack_name"`
-	} `json:"user_mappings"`
-	UserMappings userMappings
+	Assignment struct {
+		Members  []string `json:"members"`
+		Strategy *string  `json:"strategy"`
+		Routes   []struct {
+			LabelName *string  `json:"label_name"`
+			Members   []string `json:"members"`
+		} `json:"routes"`
+	} `json:"assignment"`
+	Teams []struct {
+		Name             *string         `json:"name"`
+		Organization     *string         `json:"organization"`
+		SlackNamePattern *string         `json:"slack_name_pattern"`
+		UserMappingList  userMappingList `json:"user_mappings"`
+	} `json:"teams"`
+	UserMappingList userMappingList `json:"user_mappings"`
+	UserMappings    userMappings
+	Jobs            []struct {
+		Name     *string  `json:"name"`
+		Schedule *string  `json:"schedule"`
+		Command  *string  `json:"command"`
+		Args     []string `json:"args"`
+		Output   *string  `json:"output"`
+		Retries  *int     `json:"retries"`
+	} `json:"jobs"`
+}
+
+// validate returns problems in the config such as missing fields,
+// in the form of "key path: message".
+func (c *config) validate() []string {
+
+	problems := []string{}
+	addProblem := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+	checkLevel := func(path string, level *string) {
+		if level == nil {
+			addProblem("%s: missing field", path)
+		} else if !existStr(levels, *level) {
+			addProblem("%s: undefined level (%s)", path, *level)
+		}
+	}
+
+	for i, v := range c.LabelRule.Priority {
+		if v.LabelName == nil {
+			addProblem("label_rule.priority.%d.label_name: missing field", i)
+		}
+		checkLevel(fmt.Sprintf("label_rule.priority.%d.level", i), v.Level)
+	}
+	for i, v := range c.LabelRule.Other {
+		if v.LabelName == nil {
+			addProblem("label_rule.other.%d.label_name: missing field", i)
+		}
+		checkLevel(fmt.Sprintf("label_rule.other.%d.level", i), v.Level)
+	}
+	for k := range c.LabelRule.LevelWeights {
+		if !existStr(levels, k) {
+			addProblem("label_rule.level_weights.%s: undefined level (%s)", k, k)
+		}
+	}
+	for i, v := range c.LabelRule.Size {
+		if v.LabelName == nil {
+			addProblem("label_rule.size.%d.label_name: missing field", i)
+		}
+		if v.Weight == nil {
+			addProblem("label_rule.size.%d.weight: missing field", i)
+		}
+	}
+
+	if v := c.Retention; v != nil && *v < 0 {
+		addProblem("snapshot_retention_days: negative days (%d)", *v)
+	}
+
+	if v := c.Assignment.Strategy; v != nil && *v != strategyLeastLoaded && *v != strategyRoundRobin {
+		addProblem("assignment.strategy: undefined assignment strategy (%s)", *v)
+	}
+	for i, v := range c.Assignment.Routes {
+		if v.LabelName == nil {
+			addProblem("assignment.routes.%d.label_name: missing field", i)
+		}
+	}
+
+	for i, team := range c.Teams {
+		if team.Name == nil {
+			addProblem("teams.%d.name: missing field", i)
+		}
+		for _, p := range team.UserMappingList.validate() {
+			addProblem("teams.%d.user_mappings.%s", i, p)
+		}
+	}
+	for _, p := range c.UserMappingList.validate() {
+		addProblem("user_mappings.%s", p)
+	}
+
+	for i, v := range c.Jobs {
+		if v.Command == nil {
+			addProblem("jobs.%d.command: missing field", i)
+		} else if err := checkJobCmd(*v.Command); err != nil {
+			addProblem("jobs.%d.command: %s (%s)", i, err, *v.Command)
+		}
+		if v.Schedule == nil {
+			addProblem("jobs.%d.schedule: missing field", i)
+		} else if _, err := parseCron(*v.Schedule); err != nil {
+			addProblem("jobs.%d.schedule: %s", i, err)
+		}
+	}
+
+	return problems
 }
 
 func (c *config) getPriorityLabels(level string) []string {
@@ -58,8 +183,79 @@ func (c *config) getLabels(level string) []string {
 	return labels
 }
 
+// getPriorityWeights returns the weight of each priority label
+// according to the level it belongs to.
+func (c *config) getPriorityWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for _, v := range c.LabelRule.Priority {
+		if w, ok := c.LabelRule.LevelWeights[*v.Level]; ok {
+			weights[*v.LabelName] = w
+		}
+	}
+	return weights
+}
+
+// getSizeWeights returns the weight of each size label.
+func (c *config) getSizeWeights() map[string]float64 {
+	weights := make(map[string]float64)
+	for _, v := range c.LabelRule.Size {
+		weights[*v.LabelName] = *v.Weight
+	}
+	return weights
+}
+
+func (c *config) getTeamNames() []string {
+	names := []string{}
+	for _, v := range c.Teams {
+		if v.Name != nil {
+			names = append(names, *v.Name)
+		}
+	}
+	return names
+}
+
+// getPatternTeamNames returns the names of the teams which have slack_name_pattern,
+// whose members have to be resolved for user mappings.
+func (c *config) getPatternTeamNames() []string {
+	names := []string{}
+	for _, v := range c.Teams {
+		if v.Name != nil && v.SlackNamePattern != nil {
+			names = append(names, *v.Name)
+		}
+	}
+	return names
+}
+
+type userMappingList []struct {
+	GithubName *string `json:"github_name"`
+	SlackName  *string `json:"slack_name"`
+}
+
+func (u userMappingList) validate() []string {
+	problems := []string{}
+	for i, v := range u {
+		if v.GithubName == nil {
+			problems = append(problems, fmt.Sprintf("%d.github_name: missing field", i))
+		}
+		if v.SlackName == nil {
+			problems = append(problems, fmt.Sprintf("%d.slack_name: missing field", i))
+		}
+	}
+	return problems
+}
+
 type userMappings map[string]string
 
+func (u userMappings) add(list userMappingList) error {
+	for _, userMapping := range list {
+		if _, exist := u[*userMapping.GithubName]; exist {
+			return errors.New("duplicate github_name")
+		}
+		u[*userMapping.GithubName] = *userMapping.SlackName
+	}
+	return nil
+}
+
 func (u *userMappings) getValue(key string) string {
 	if u == nil {
 		return key
@@ -82,36 +278,20 @@ func (u *userMappings) getValues(keys []string) []string {
 
 func readConfig(c *cli.Context) (*config, error) {
 
-	conf := new(config)
-
-	jsonStr, err := ioutil.ReadFile(c.GlobalString("config"))
+	conf, _, _, err := resolveConfig(c)
 	if err != nil {
-		return nil, fmt.Errorf("not found config file (%s)", c.GlobalString("config"))
-	}
-
-	err = json.Unmarshal(jsonStr, conf)
-	if err != nil {
-		return nil, fmt.Errorf("something wrong in config file (%s)", c.GlobalString("config"))
-	}
-
-	// if a value is specified with a command line argument, use that value
-	if str := c.GlobalString("user"); str != "" {
-		conf.User = &str
-	}
-	if str := c.GlobalString("repo"); str != "" {
-		conf.Repo = &str
-	}
-	if str := c.GlobalString("token"); str != "" {
-		conf.Token = &str
+		return nil, err
 	}
 
 	// create UserMappings
 	conf.UserMappings = make(map[string]string)
-	for _, userMapping := range conf.UserMappingList {
-		if _, exist := conf.UserMappings[*userMapping.GithubName]; exist {
-			return nil, errors.New("duplicate github_name")
+	if err := conf.UserMappings.add(conf.UserMappingList); err != nil {
+		return nil, err
+	}
+	for _, team := range conf.Teams {
+		if err := conf.UserMappings.add(team.UserMappingList); err != nil {
+			return nil, err
 		}
-		conf.UserMappings[*userMapping.GithubName] = *userMapping.SlackName
 	}
 
 	// user and repo is mandatory
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	envPrefix           = "GITHUBMGR_"
+	userConfigDir       = "githubmgr"
+	userConfigName      = "config"
+	repoConfigName      = ".githubmgr"
+	sourceEnvironment   = "environment"
+	sourceCommandLine   = "command line"
+	configSourceUnknown = "unknown"
+	configKeySeparator  = "."
+)
+
+// configFileExts are extensions of user-level and repo-local config files in the order of search.
+var configFileExts = []string{"", ".json", ".yaml", ".yml", ".toml"}
+
+// configLayer is a set of config values and where they come from.
+type configLayer struct {
+	Source string
+	Values map[string]interface{}
+}
+
+// loadConfigLayers returns the layers of configuration in the order of priority (lowest first):
+// git remote of the working directory, user-level config file, repo-local config file, config file, environment variables and command line options.
+func loadConfigLayers(c *cli.Context) ([]configLayer, error) {
+
+	files := []string{}
+	if dir, err := userConfigPath(); err == nil {
+		files = append(files, findConfigFile(dir, userConfigName))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		for dir := wd; ; dir = filepath.Dir(dir) {
+			if f := findConfigFile(dir, repoConfigName); f != "" {
+				files = append(files, f)
+				break
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+
+	// the config file is optional if it is not specified explicitly
+	configFile := c.GlobalString("config")
+	if _, err := os.Stat(configFile); err == nil {
+		files = append(files, configFile)
+	} else if c.GlobalIsSet("config") {
+		return nil, fmt.Errorf("not found config file (%s)", configFile)
+	}
+
+	layers := []configLayer{}
+	fileFound := false
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		l, err := loadConfigFileLayer(f)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, l)
+		fileFound = true
+	}
+
+	// environment variables such as GITHUBMGR_USERNAME for top level string values
+	env := configLayer{Source: sourceEnvironment, Values: make(map[string]interface{})}
+	t := reflect.TypeOf(config{})
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key == "" || t.Field(i).Type != reflect.TypeOf((*string)(nil)) {
+			continue
+		}
+		if v, ok := os.LookupEnv(envPrefix + strings.ToUpper(key)); ok {
+			env.Values[key] = v
+		}
+	}
+	layers = append(layers, env)
+
+	// if a value is specified with a command line argument, use that value
+	flags := configLayer{Source: sourceCommandLine, Values: make(map[string]interface{})}
+	for flag, key := range map[string]string{"user": "username", "repo": "repository", "token": "access_token"} {
+		if str := c.GlobalString(flag); str != "" {
+			flags.Values[key] = str
+		}
+	}
+	layers = append(layers, flags)
+
+	// the git remote is used only if no other layers specify username or repository,
+	// and base_url inferred from it is not used if other layers specify it
+	specified, baseURLSpecified := false, false
+	for _, l := range layers {
+		if l.Values["username"] != nil || l.Values["repository"] != nil {
+			specified = true
+		}
+		if l.Values["base_url"] != nil {
+			baseURLSpecified = true
+		}
+	}
+	if !specified {
+		if l, ok := gitRemoteLayer(); ok {
+			if baseURLSpecified {
+				delete(l.Values, "base_url")
+			}
+			return append([]configLayer{l}, layers...), nil
+		}
+	}
+	if !fileFound {
+		return nil, fmt.Errorf("not found config file (%s)", configFile)
+	}
+
+	return layers, nil
+}
+
+// userConfigPath returns the directory of user-level files.
+func userConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, userConfigDir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", userConfigDir), nil
+}
+
+// findConfigFile returns the first existing file of the name with configFileExts in the directory.
+func findConfigFile(dir, name string) string {
+	for _, ext := range configFileExts {
+		f := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(f); err == nil && !info.IsDir() {
+			return f
+		}
+	}
+	return ""
+}
+
+func loadConfigFileLayer(filename string) (configLayer, error) {
+
+	// decode into config once to report errors with the position
+	if err := decodeFile(filename, new(config)); err != nil {
+		return configLayer{}, fmt.Errorf("something wrong in config file (%s): %s", filename, err)
+	}
+
+	generic, err := decodeFileGeneric(filename)
+	if err != nil {
+		return configLayer{}, fmt.Errorf("something wrong in config file (%s): %s", filename, err)
+	}
+	values, ok := generic.(map[string]interface{})
+	if !ok {
+		return configLayer{}, fmt.Errorf("something wrong in config file (%s): top level must be an object", filename)
+	}
+
+	return configLayer{Source: filename, Values: values}, nil
+}
+
+// resolveConfig merges the layers and returns the config, the merged values and the source of each key path.
+// Objects are merged recursively, and other values are overwritten by the higher layer.
+func resolveConfig(c *cli.Context) (*config, map[string]interface{}, configSources, error) {
+
+	layers, err := loadConfigLayers(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	merged, sources := mergeConfigLayers(layers)
+
+	conf := new(config)
+	if err := decodeGeneric(merged, conf); err != nil {
+		return nil, nil, nil, fmt.Errorf("something wrong in config: %s", err)
+	}
+
+	if problems := conf.validate(); len(problems) > 0 {
+		key := strings.Split(problems[0], ":")[0]
+		return nil, nil, nil, fmt.Errorf("something wrong in config (%s): %s", sources.get(key), problems[0])
+	}
+
+	return conf, merged, sources, nil
+}
+
+// mergeConfigLayers returns the merged values of the layers and the source of each key path.
+func mergeConfigLayers(layers []configLayer) (map[string]interface{}, configSources) {
+	merged := make(map[string]interface{})
+	sources := make(configSources)
+	for _, l := range layers {
+		mergeConfigValues(merged, l.Values)
+		for k := range flattenConfigValues(l.Values, "") {
+			sources[k] = l.Source
+		}
+	}
+	return merged, sources
+}
+
+func mergeConfigValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok1 := v.(map[string]interface{})
+		dstMap, ok2 := dst[k].(map[string]interface{})
+		if ok1 && ok2 {
+			mergeConfigValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = copyConfigValue(v)
+	}
+}
+
+// copyConfigValue returns a deep copy of the value, so merged values don't share maps and slices with layers.
+func copyConfigValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, v2 := range v {
+			m[k] = copyConfigValue(v2)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, v2 := range v {
+			list[i] = copyConfigValue(v2)
+		}
+		return list
+	case []map[string]interface{}:
+		list := make([]map[string]interface{}, len(v))
+		for i, v2 := range v {
+			list[i] = copyConfigValue(v2).(map[string]interface{})
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// flattenConfigValues returns leaf values by key path. Arrays are treated as leaves.
+func flattenConfigValues(values map[string]interface{}, prefix string) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for k, v := range values {
+		key := prefix + k
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			for k2, v2 := range flattenConfigValues(m, key+configKeySeparator) {
+				flat[k2] = v2
+			}
+			continue
+		}
+		flat[key] = v
+	}
+	return flat
+}
+
+// configSources is a map of key path and the source of the value.
+type configSources map[string]string
+
+// get returns the source of the key path or its nearest parent.
+func (s configSources) get(key string) string {
+	for {
+		if src, ok := s[key]; ok {
+			return src
+		}
+		idx := strings.LastIndex(key, configKeySeparator)
+		if idx < 0 {
+			return configSourceUnknown
+		}
+		key = key[:idx]
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfigLayers(t *testing.T) {
+
+	layer := func(source, src string) configLayer {
+		l := configLayer{Source: source}
+		if err := json.Unmarshal([]byte(src), &l.Values); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	layers := []configLayer{
+		layer("user", `{"username": "u1", "label_rule": {"level_weights": {"High": 5, "Low": 1}}, "assignment": {"members": ["a", "b"]}}`),
+		layer("repo", `{"repository": "r2", "label_rule": {"level_weights": {"High": 3}}}`),
+		layer("config", `{"username": "u3", "assignment": {"members": ["c"]}}`),
+	}
+	before := []map[string]interface{}{}
+	for _, l := range layers {
+		before = append(before, copyConfigValue(l.Values).(map[string]interface{}))
+	}
+
+	merged, sources := mergeConfigLayers(layers)
+
+	expected := layer("", `{
+		"username": "u3",
+		"repository": "r2",
+		"label_rule": {"level_weights": {"High": 3, "Low": 1}},
+		"assignment": {"members": ["c"]}
+	}`).Values
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("merged values are %v, expected %v", merged, expected)
+	}
+
+	expectedSources := configSources{
+		"username":                      "config",
+		"repository":                    "repo",
+		"label_rule.level_weights.High": "repo",
+		"label_rule.level_weights.Low":  "user",
+		"assignment.members":            "config",
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("sources are %v, expected %v", sources, expectedSources)
+	}
+
+	// merging doesn't change the layers, and merged values don't share maps and slices with them
+	for i, l := range layers {
+		if !reflect.DeepEqual(l.Values, before[i]) {
+			t.Errorf("values of layer `%s` are changed to %v, expected %v", l.Source, l.Values, before[i])
+		}
+	}
+	merged["label_rule"].(map[string]interface{})["level_weights"].(map[string]interface{})["Low"] = 2.0
+	merged["assignment"].(map[string]interface{})["members"].([]interface{})[0] = "d"
+	for i, l := range layers {
+		if !reflect.DeepEqual(l.Values, before[i]) {
+			t.Errorf("values of layer `%s` are changed with merged values to %v", l.Source, l.Values)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+const jsonSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+func configSchemaCmd() cli.Command {
+	return cli.Command{
+		Name:      "schema",
+		Usage:     "output json schema of config file or label settings file",
+		ArgsUsage: "[config|label]",
+		Action: func(c *cli.Context) error {
+			return configSchema{Out: c.App.Writer}.Run(c.Args().First())
+		},
+	}
+}
+
+type configSchema struct {
+	Out io.Writer
+}
+
+func (s configSchema) Run(target string) error {
+
+	var title string
+	var t reflect.Type
+	switch target {
+	case "", "config":
+		title, t = "githubmgr config", reflect.TypeOf(config{})
+	case "label":
+		title, t = "githubmgr label settings", reflect.TypeOf(labelSetting{})
+	default:
+		return fmt.Errorf("undefined schema target (%s)", target)
+	}
+
+	schema := jsonSchema(t)
+	schema["$schema"] = jsonSchemaVersion
+	schema["title"] = title
+
+	jsonStr, err := json.MarshalIndent(schema, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(s.Out, string(jsonStr))
+
+	return nil
+}
+
+// jsonSchema creates json schema from the type with json tags.
+// Fields without json tag are not included.
+func jsonSchema(t reflect.Type) map[string]interface{} {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if f.PkgPath != "" || name == "" || name == "-" {
+				continue
+			}
+			props[name] = jsonSchema(f.Type)
+		}
+		return map[string]interface{}{"type": "object", "properties": props, "additionalProperties": false}
+	default:
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/urfave/cli"
+)
+
+const maskedValue = "********"
+
+func configShowCmd() cli.Command {
+	return cli.Command{
+		Name:  "show",
+		Usage: "output config layers or effective config values",
+		Action: func(c *cli.Context) error {
+			return configShow{Out: c.App.Writer}.Run(c)
+		},
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "resolved",
+				Usage: "output effective values and their sources",
+			},
+		},
+	}
+}
+
+type configShow struct {
+	Out io.Writer
+}
+
+func (s configShow) Run(c *cli.Context) error {
+
+	if !c.Bool("resolved") {
+		layers, err := loadConfigLayers(c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(s.Out, "# Config layers (lowest priority first)")
+		for _, l := range layers {
+			fmt.Fprintf(s.Out, "    %s (keys=%d)\n", l.Source, len(flattenConfigValues(l.Values, "")))
+		}
+		return nil
+	}
+
+	_, merged, sources, err := resolveConfig(c)
+	if err != nil {
+		return err
+	}
+
+	flat := flattenConfigValues(merged, "")
+	keys := []string{}
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(s.Out, "# Resolved config")
+	for _, k := range keys {
+		value := maskedValue
+		if k != "access_token" {
+			jsonStr, err := json.Marshal(flat[k])
+			if err != nil {
+				return err
+			}
+			value = string(jsonStr)
+		}
+		fmt.Fprintf(s.Out, "    %s = %s (%s)\n", k, value, sources.get(k))
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+func configTemplateCmd() cli.Command {
+	return cli.Command{
+		Name:  "template",
+		Usage: "output default template of issue report to customize it",
+		Action: func(c *cli.Context) error {
+			_, err := fmt.Fprint(c.App.Writer, defaultReportTemplate)
+			return err
+		},
+	}
+}
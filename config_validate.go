@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func configValidateCmd() cli.Command {
+	return cli.Command{
+		Name:  "validate",
+		Usage: "check config resolved from all layers and label settings file, and exit with non-zero status if any problems",
+		Action: func(c *cli.Context) error {
+			return configValidate{Out: c.App.Writer}.Run(c)
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "label_settings.json",
+				Usage: "you can change label setting json file",
+			},
+		},
+	}
+}
+
+// resolvedConfigSource is the source of problems which don't belong to any layer.
+const resolvedConfigSource = "resolved config"
+
+type configValidate struct {
+	Out io.Writer
+}
+
+// sourceProblems is problems grouped by the source, in the order of sources.
+type sourceProblems struct {
+	Sources  []string
+	Problems map[string][]string
+}
+
+func (p *sourceProblems) addSource(source string) {
+	if p.Problems == nil {
+		p.Problems = make(map[string][]string)
+	}
+	if _, ok := p.Problems[source]; !ok {
+		p.Sources = append(p.Sources, source)
+		p.Problems[source] = []string{}
+	}
+}
+
+func (p *sourceProblems) add(source, problem string) {
+	p.addSource(source)
+	p.Problems[source] = append(p.Problems[source], problem)
+}
+
+func (p *sourceProblems) count() int {
+	cnt := 0
+	for _, v := range p.Problems {
+		cnt += len(v)
+	}
+	return cnt
+}
+
+func (v configValidate) Run(c *cli.Context) error {
+
+	settingFile := c.String("file")
+
+	confProblems := &sourceProblems{}
+	conf, confSources := v.resolve(c, confProblems)
+
+	setting := new(labelSetting)
+	settingProblems, settingOK := v.check(settingFile, setting)
+	if settingOK {
+		settingProblems = append(settingProblems, setting.validate()...)
+	}
+	if len(settingProblems) == 0 {
+		// duplicates, references and extended settings are checked when reading
+		var client *github.Client
+		if conf != nil {
+			client, _ = newClient(conf, nil)
+		}
+		readSetting, err := (label{}).ReadSettings(settingFile, client)
+		if err != nil {
+			settingProblems = append(settingProblems, err.Error())
+		} else {
+			setting = readSetting
+			settingProblems = append(settingProblems, setting.contrastProblems()...)
+		}
+	}
+
+	// label references in label_rule
+	if conf != nil && confProblems.count() == 0 && len(settingProblems) == 0 {
+		check := func(path string, name *string) {
+			if _, ok := setting.LabelMap[*name]; !ok && !setting.isIgnored(*name) {
+				confProblems.add(confSources.get(path), fmt.Sprintf("%s: label is not found in label settings (%s)", path, *name))
+			}
+		}
+		for i, r := range conf.LabelRule.Priority {
+			check(fmt.Sprintf("label_rule.priority.%d.label_name", i), r.LabelName)
+		}
+		for i, r := range conf.LabelRule.Other {
+			check(fmt.Sprintf("label_rule.other.%d.label_name", i), r.LabelName)
+		}
+		for i, r := range conf.LabelRule.Size {
+			check(fmt.Sprintf("label_rule.size.%d.label_name", i), r.LabelName)
+		}
+	}
+
+	// output
+	fmt.Fprintln(v.Out, "# Config validation")
+	for _, source := range confProblems.Sources {
+		v.outputProblems(source, confProblems.Problems[source])
+	}
+	v.outputProblems(settingFile, settingProblems)
+
+	if cnt := confProblems.count() + len(settingProblems); cnt > 0 {
+		return fmt.Errorf("there are problems in config files (problems=%d)", cnt)
+	}
+	return nil
+}
+
+// resolve validates the config resolved from all layers in the same way as other commands.
+// Problems are added to the layer which the value comes from.
+// It returns nil if the config can't be resolved.
+func (v configValidate) resolve(c *cli.Context, problems *sourceProblems) (*config, configSources) {
+
+	layers, err := loadConfigLayers(c)
+	if err != nil {
+		problems.add(resolvedConfigSource, err.Error())
+		return nil, nil
+	}
+
+	for _, l := range layers {
+		if len(l.Values) == 0 {
+			continue
+		}
+		problems.addSource(l.Source)
+		for _, key := range unknownKeys(l.Values, reflect.TypeOf(config{}), "") {
+			problems.add(l.Source, fmt.Sprintf("%s: unknown key", key))
+		}
+	}
+
+	merged, sources := mergeConfigLayers(layers)
+	source := func(key string) string {
+		if src := sources.get(key); src != configSourceUnknown {
+			return src
+		}
+		return resolvedConfigSource
+	}
+
+	conf := new(config)
+	if err := decodeGeneric(merged, conf); err != nil {
+		problems.add(resolvedConfigSource, err.Error())
+		return nil, nil
+	}
+
+	confProblems := conf.validate()
+	for _, p := range confProblems {
+		problems.add(source(strings.Split(p, ":")[0]), p)
+	}
+	if len(confProblems) == 0 {
+		mappings := make(userMappings)
+		if err := mappings.add(conf.UserMappingList); err != nil {
+			problems.add(source("user_mappings"), fmt.Sprintf("user_mappings: %s", err))
+		}
+		for i, team := range conf.Teams {
+			if err := mappings.add(team.UserMappingList); err != nil {
+				problems.add(source(fmt.Sprintf("teams.%d.user_mappings", i)), fmt.Sprintf("teams.%d.user_mappings: %s", i, err))
+			}
+		}
+	}
+	if conf.User == nil {
+		problems.add(resolvedConfigSource, "username: missing field")
+	}
+	if conf.Repo == nil {
+		problems.add(resolvedConfigSource, "repository: missing field")
+	}
+
+	return conf, sources
+}
+
+// check decodes the file and returns problems such as unknown keys.
+// If the file can't be decoded, false is returned with the parse error.
+func (v configValidate) check(filename string, target interface{}) ([]string, bool) {
+
+	if _, err := os.Stat(filename); err != nil {
+		return []string{fmt.Sprintf("not found config file (%s)", filename)}, false
+	}
+
+	if err := decodeFile(filename, target); err != nil {
+		return []string{err.Error()}, false
+	}
+
+	generic, err := decodeFileGeneric(filename)
+	if err != nil {
+		return []string{err.Error()}, false
+	}
+
+	problems := []string{}
+	for _, key := range unknownKeys(generic, reflect.TypeOf(target), "") {
+		problems = append(problems, fmt.Sprintf("%s: unknown key", key))
+	}
+	return problems, true
+}
+
+func (v configValidate) outputProblems(filename string, problems []string) {
+	fmt.Fprintf(v.Out, "  * %s\n", filename)
+	if len(problems) == 0 {
+		fmt.Fprintln(v.Out, "    ok")
+		return
+	}
+	for _, p := range problems {
+		fmt.Fprintf(v.Out, "    - %s\n", p)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression with 5 fields
+// (minute, hour, day of month, month and day of week).
+type cronSchedule struct {
+	Minute, Hour, Dom, Month, Dow map[int]bool
+	domAny, dowAny                bool
+}
+
+var cronFieldRanges = []struct {
+	Name     string
+	Min, Max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFieldRanges) {
+		return nil, fmt.Errorf("cron expression must have %d fields (%s)", len(cronFieldRanges), expr)
+	}
+
+	values := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		r := cronFieldRanges[i]
+		v, err := parseCronField(field, r.Min, r.Max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in cron expression (%s): %s", r.Name, expr, err)
+		}
+		values[i] = v
+	}
+
+	// both 0 and 7 are Sunday
+	if values[4][7] {
+		values[4][0] = true
+	}
+
+	return &cronSchedule{
+		Minute: values[0],
+		Hour:   values[1],
+		Dom:    values[2],
+		Month:  values[3],
+		Dow:    values[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return nil, fmt.Errorf("invalid step (%s)", part)
+			}
+			step = s
+			part = part[:idx]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			f, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value (%s)", part)
+			}
+			from, to = f, f
+			if len(bounds) == 2 {
+				t, err := strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value (%s)", part)
+				}
+				to = t
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || max < to || to < from {
+			return nil, fmt.Errorf("out of range (%s)", part)
+		}
+
+		for v := from; v <= to; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// Next returns the first time after t which matches the schedule.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// a matching time always exists within 5 years (e.g. Feb 29)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.Month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.Hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.Minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay follows the cron convention that if both day of month and day of week
+// are restricted, either of them matching is enough.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom, dow := s.Dom[t.Day()], s.Dow[int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
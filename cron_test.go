@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseCronError(t *testing.T) {
+
+	cases := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"a * * * *",
+		"1-a * * * *",
+		"10-5 * * * *",
+		"*/0 * * * *",
+		"*/a * * * *",
+		"1,,2 * * * *",
+	}
+
+	for _, expr := range cases {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("%q: parseCron doesn't return error", expr)
+		}
+	}
+}
+
+func TestParseCronField(t *testing.T) {
+
+	cases := []struct {
+		field    string
+		min, max int
+		expected []int
+	}{
+		{"5", 0, 59, []int{5}},
+		{"1,3,5", 0, 59, []int{1, 3, 5}},
+		{"10-13", 0, 59, []int{10, 11, 12, 13}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"10-30/10", 0, 59, []int{10, 20, 30}},
+		{"50/5", 0, 59, []int{50, 55}},
+		{"*", 1, 12, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"*/5", 1, 12, []int{1, 6, 11}},
+		{"1-3,10-11", 1, 31, []int{1, 2, 3, 10, 11}},
+	}
+
+	for _, c := range cases {
+		values, err := parseCronField(c.field, c.min, c.max)
+		if err != nil {
+			t.Errorf("%q: parseCronField returns error: %s", c.field, err)
+			continue
+		}
+		list := []int{}
+		for v := range values {
+			list = append(list, v)
+		}
+		sort.Ints(list)
+		if !reflect.DeepEqual(list, c.expected) {
+			t.Errorf("%q: values are %v, expected %v", c.field, list, c.expected)
+		}
+	}
+}
+
+func TestParseCronSunday(t *testing.T) {
+
+	for _, expr := range []string{"0 0 * * 0", "0 0 * * 7"} {
+		s, err := parseCron(expr)
+		if err != nil {
+			t.Fatalf("%q: parseCron returns error: %s", expr, err)
+		}
+		if !s.Dow[0] {
+			t.Errorf("%q: sunday is not included in day of week", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+
+	date := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		// next minute, and seconds are truncated
+		{"* * * * *", date(2018, 3, 1, 10, 0).Add(30 * time.Second), date(2018, 3, 1, 10, 1)},
+		// the same time is not included
+		{"0 9 * * *", date(2018, 3, 1, 9, 0), date(2018, 3, 2, 9, 0)},
+		{"*/15 * * * *", date(2018, 3, 1, 10, 16), date(2018, 3, 1, 10, 30)},
+		{"30 9-17/4 * * *", date(2018, 3, 1, 13, 31), date(2018, 3, 1, 17, 30)},
+		// day boundary
+		{"0 9 * * *", date(2018, 3, 1, 23, 59), date(2018, 3, 2, 9, 0)},
+		// month boundary
+		{"0 0 1 * *", date(2018, 1, 31, 12, 0), date(2018, 2, 1, 0, 0)},
+		{"0 0 31 * *", date(2018, 4, 1, 0, 0), date(2018, 5, 31, 0, 0)},
+		// year boundary
+		{"0 0 * * *", date(2018, 12, 31, 23, 30), date(2019, 1, 1, 0, 0)},
+		{"0 0 1 1 *", date(2018, 6, 1, 0, 0), date(2019, 1, 1, 0, 0)},
+		// leap year
+		{"0 0 29 2 *", date(2018, 3, 1, 0, 0), date(2020, 2, 29, 0, 0)},
+		// day of week (2018-03-01 is thursday)
+		{"0 9 * * 1", date(2018, 3, 1, 10, 0), date(2018, 3, 5, 9, 0)},
+		{"0 9 * * 1-5", date(2018, 3, 2, 10, 0), date(2018, 3, 5, 9, 0)},
+		{"0 9 * * 0", date(2018, 3, 1, 10, 0), date(2018, 3, 4, 9, 0)},
+		{"0 9 * * 7", date(2018, 3, 1, 10, 0), date(2018, 3, 4, 9, 0)},
+		// day of week across month boundary (2018-03-31 is saturday)
+		{"0 9 * * 1", date(2018, 3, 27, 10, 0), date(2018, 4, 2, 9, 0)},
+		// either day of month or day of week matches if both are restricted
+		{"0 0 15 * 1", date(2018, 3, 6, 0, 0), date(2018, 3, 12, 0, 0)},
+		{"0 0 15 * 1", date(2018, 3, 12, 0, 0), date(2018, 3, 15, 0, 0)},
+		// month and day of week
+		{"0 0 * 6 1", date(2018, 3, 1, 0, 0), date(2018, 6, 4, 0, 0)},
+	}
+
+	for _, c := range cases {
+		s, err := parseCron(c.expr)
+		if err != nil {
+			t.Errorf("%q: parseCron returns error: %s", c.expr, err)
+			continue
+		}
+		if next := s.Next(c.from); !next.Equal(c.expected) {
+			t.Errorf("%q: Next(%s) is %s, expected %s", c.expr, c.from, next, c.expected)
+		}
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+
+	s, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parseCron returns error: %s", err)
+	}
+	if next := s.Next(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("Next is %s, expected zero time", next)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// fixtureHeaders are response headers saved in fixtures.
+// Link is required for pagination.
+var fixtureHeaders = []string{"Content-Type", "Link"}
+
+var fixtureNamePtn = regexp.MustCompile("[^0-9A-Za-z.-]+")
+
+// fixtureTransport saves responses to Dir in record mode, or serves them from Dir in replay mode.
+type fixtureTransport struct {
+	Dir    string
+	Replay bool
+	Base   http.RoundTripper
+}
+
+// fixture is the file format of a recorded response.
+type fixture struct {
+	Method string            `json:"method"`
+	URL    string            `json:"url"`
+	Status int               `json:"status"`
+	Header map[string]string `json:"header"`
+	Body   string            `json:"body"`
+}
+
+// newFixtureTransport returns the transport for the record or replay directory, or nil if neither is set.
+func newFixtureTransport(record, replay string) (*fixtureTransport, error) {
+	switch {
+	case record != "" && replay != "":
+		return nil, fmt.Errorf("record and replay can't be set at the same time")
+	case record != "":
+		if err := os.MkdirAll(record, 0755); err != nil {
+			return nil, fmt.Errorf("unable to create record directory (%s)", record)
+		}
+		return &fixtureTransport{Dir: record}, nil
+	case replay != "":
+		if _, err := os.Stat(replay); err != nil {
+			return nil, fmt.Errorf("not found replay directory (%s)", replay)
+		}
+		return &fixtureTransport{Dir: replay, Replay: true}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// filename returns the fixture file of the request,
+// which is unique for the method, the path with query and the body.
+func (t *fixtureTransport) filename(req *http.Request, body []byte) string {
+	uri := req.URL.RequestURI()
+	hash := sha1.Sum(append([]byte(req.Method+" "+uri+"\n"), body...))
+	name := strings.Trim(fixtureNamePtn.ReplaceAllString(req.URL.Path, "_"), "_")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return filepath.Join(t.Dir, fmt.Sprintf("%s_%s_%x.json", req.Method, name, hash[:4]))
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	filename := t.filename(req, body)
+
+	if t.Replay {
+		return t.replay(req, filename)
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	f := fixture{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Status: resp.StatusCode,
+		Header: make(map[string]string),
+		Body:   string(respBody),
+	}
+	for _, v := range fixtureHeaders {
+		if h := resp.Header.Get(v); h != "" {
+			f.Header[v] = h
+		}
+	}
+	jsonStr, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filename, append(jsonStr, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("unable to write fixture file (%s)", filename)
+	}
+
+	return resp, nil
+}
+
+func (t *fixtureTransport) replay(req *http.Request, filename string) (*http.Response, error) {
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("not found recorded response (%s %s, %s)", req.Method, req.URL.RequestURI(), filepath.Base(filename))
+	}
+	f := fixture{}
+	if err := json.Unmarshal(src, &f); err != nil {
+		return nil, fmt.Errorf("something wrong in fixture file (%s)", filename)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+	for k, v := range f.Header {
+		resp.Header.Set(k, v)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fixtureDir has the responses of issue, assign and label commands for octo-org/octo-repo in testdata/config.json.
+// They are not recorded from github, but written by hand in the file format of --record.
+const fixtureDir = "testdata/replay"
+
+// setTempConfigHome sets XDG_CONFIG_HOME to a temporary directory not to read or write the user-level files,
+// and returns the function to restore it.
+func setTempConfigHome(t *testing.T) func() {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, ok := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	return func() {
+		if ok {
+			os.Setenv("XDG_CONFIG_HOME", prev)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// runReplay runs the command with the responses in fixtureDir and returns the output.
+func runReplay(t *testing.T, args ...string) string {
+	t.Helper()
+	defer setTempConfigHome(t)()
+
+	out := &bytes.Buffer{}
+	app := newApp()
+	app.Writer = out
+	args = append([]string{"githubmgr", "--config", "testdata/config.json", "--replay", fixtureDir}, args...)
+	if err := app.Run(args); err != nil {
+		t.Fatalf("%s: %s", strings.Join(args[5:], " "), err)
+	}
+	return out.String()
+}
+
+func TestFixtureReplayNotRecorded(t *testing.T) {
+
+	defer setTempConfigHome(t)()
+
+	app := newApp()
+	app.Writer = &bytes.Buffer{}
+	err := app.Run([]string{"githubmgr", "--config", "testdata/config.json", "--user", "other", "--replay", fixtureDir, "assign"})
+	if err == nil || !strings.Contains(err.Error(), "not found recorded response (GET /repos/other/octo-repo/issues") {
+		t.Errorf("request which isn't recorded returns unexpected error: %v", err)
+	}
+}
+
+func TestFixtureTransport(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		record, replay string
+		valid, isNil   bool
+	}{
+		{"", "", true, true},
+		{dir, "", true, false},
+		{"", fixtureDir, true, false},
+		{"", "testdata/undefined", false, true},
+		{dir, fixtureDir, false, true},
+	}
+
+	for _, c := range cases {
+		ft, err := newFixtureTransport(c.record, c.replay)
+		if c.valid != (err == nil) {
+			t.Errorf("record=%q, replay=%q: error is %v", c.record, c.replay, err)
+		}
+		if c.isNil != (ft == nil) {
+			t.Errorf("record=%q, replay=%q: transport is %v", c.record, c.replay, ft)
+		}
+		if ft != nil && ft.Replay != (c.replay != "") {
+			t.Errorf("record=%q, replay=%q: replay mode is %t", c.record, c.replay, ft.Replay)
+		}
+	}
+}
+
+func TestFixtureRecordReplay(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Link", `<https://api.github.com/repos/o/r/issues?page=2>; rel="next"`)
+		w.Header().Set("X-Secret", "secret")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"request": "` + r.Method + " " + r.URL.RequestURI() + " " + string(body) + `"}`))
+	}))
+	defer api.Close()
+
+	requests := []struct {
+		method, uri, body string
+	}{
+		{http.MethodGet, "/repos/o/r/issues?page=1", ""},
+		{http.MethodPost, "/repos/o/r/issues/1/labels", "bug"},
+		{http.MethodPost, "/repos/o/r/issues/1/labels", "wontfix"},
+	}
+	do := func(ft *fixtureTransport, method, uri, body string) (*http.Response, string, error) {
+		req, _ := http.NewRequest(method, api.URL+uri, strings.NewReader(body))
+		resp, err := ft.RoundTrip(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(respBody), nil
+	}
+
+	record, _ := newFixtureTransport(dir, "")
+	recorded := []string{}
+	for _, r := range requests {
+		_, body, err := do(record, r.method, r.uri, r.body)
+		if err != nil {
+			t.Fatalf("%s %s: record returns error: %s", r.method, r.uri, err)
+		}
+		recorded = append(recorded, body)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != len(requests) {
+		t.Errorf("number of fixture files is %d, expected %d", len(files), len(requests))
+	}
+
+	api.Close()
+	replay, _ := newFixtureTransport("", dir)
+	for i, r := range requests {
+		resp, body, err := do(replay, r.method, r.uri, r.body)
+		if err != nil {
+			t.Errorf("%s %s: replay returns error: %s", r.method, r.uri, err)
+			continue
+		}
+		if body != recorded[i] || resp.StatusCode != http.StatusCreated {
+			t.Errorf("%s %s: replayed response is %d %q, expected %d %q", r.method, r.uri, resp.StatusCode, body, http.StatusCreated, recorded[i])
+		}
+		if resp.Header.Get("Link") == "" || resp.Header.Get("X-Secret") != "" {
+			t.Errorf("%s %s: replayed headers are %v", r.method, r.uri, resp.Header)
+		}
+	}
+
+	if _, _, err := do(replay, http.MethodGet, "/repos/o/r/issues?page=2", ""); err == nil {
+		t.Error("request which isn't recorded doesn't return error")
+	}
+}
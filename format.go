@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+)
+
+// fileFormat returns the format of the file by its extension.
+// Unknown extensions are treated as json.
+func fileFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return formatJSON
+	}
+}
+
+// decodeFile decodes the json, yaml or toml file into v.
+// yaml and toml are converted to json once, so only json tags are needed for v.
+func decodeFile(filename string, v interface{}) error {
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return decodeBytes(filename, src, v)
+}
+
+// decodeBytes decodes src into v by the extension of the filename.
+func decodeBytes(filename string, src []byte, v interface{}) error {
+	switch fileFormat(filename) {
+	case formatYAML:
+		return decodeYAML(src, v)
+	case formatTOML:
+		return decodeTOML(src, v)
+	default:
+		return decodeJSON(src, v)
+	}
+}
+
+// decodeError is an error in a setting file with its position and key path.
+type decodeError struct {
+	Line, Column int
+	Key          string
+	Msg          string
+}
+
+func (e *decodeError) Error() string {
+	str := e.Msg
+	if e.Line > 0 {
+		str = fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, str)
+	}
+	if e.Key != "" {
+		str += fmt.Sprintf(" (key=%s)", e.Key)
+	}
+	return str
+}
+
+func decodeJSON(src []byte, v interface{}) error {
+
+	err := json.Unmarshal(src, v)
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *json.SyntaxError:
+		line, col := lineColumn(src, int(e.Offset))
+		return &decodeError{Line: line, Column: col, Msg: e.Error()}
+	case *json.UnmarshalTypeError:
+		line, col := lineColumn(src, int(e.Offset))
+		return &decodeError{Line: line, Column: col, Key: e.Field, Msg: typeErrorMsg(e)}
+	default:
+		return err
+	}
+}
+
+func decodeYAML(src []byte, v interface{}) error {
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(src, &node); err != nil {
+		return err
+	}
+
+	var generic interface{}
+	if err := node.Decode(&generic); err != nil {
+		return err
+	}
+
+	err := decodeGeneric(generic, v)
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		dErr := &decodeError{Key: e.Field, Msg: typeErrorMsg(e)}
+		if n := findYAMLNode(&node, e.Field); n != nil {
+			dErr.Line, dErr.Column = n.Line, n.Column
+		}
+		return dErr
+	}
+	return err
+}
+
+func decodeTOML(src []byte, v interface{}) error {
+
+	var generic map[string]interface{}
+	if _, err := toml.Decode(string(src), &generic); err != nil {
+		if e, ok := err.(toml.ParseError); ok {
+			_, col := lineColumn(src, e.Position.Start)
+			msg := e.Message
+			if msg == "" {
+				// ParseError doesn't expose the original error, so trim the position from the message
+				prefix := fmt.Sprintf("toml: line %d", e.Position.Line)
+				if e.LastKey != "" {
+					prefix += fmt.Sprintf(" (last key %q)", e.LastKey)
+				}
+				msg = strings.TrimPrefix(e.Error(), prefix+": ")
+			}
+			return &decodeError{Line: e.Position.Line, Column: col, Key: e.LastKey, Msg: msg}
+		}
+		return err
+	}
+
+	// toml doesn't keep the position of values, so only the key is reported
+	err := decodeGeneric(generic, v)
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		return &decodeError{Key: e.Field, Msg: typeErrorMsg(e)}
+	}
+	return err
+}
+
+// decodeFileGeneric decodes the json, yaml or toml file into maps and slices.
+func decodeFileGeneric(filename string) (interface{}, error) {
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	switch fileFormat(filename) {
+	case formatYAML:
+		err = yaml.Unmarshal(src, &generic)
+	case formatTOML:
+		var m map[string]interface{}
+		_, err = toml.Decode(string(src), &m)
+		generic = m
+	default:
+		err = json.Unmarshal(src, &generic)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return generic, nil
+}
+
+// unknownKeys returns key paths in generic which are not defined as json tags of the type.
+func unknownKeys(generic interface{}, t reflect.Type, path string) []string {
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	keys := []string{}
+	v := reflect.ValueOf(generic)
+	switch {
+	case v.Kind() == reflect.Map && t.Kind() == reflect.Struct:
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if t.Field(i).PkgPath == "" && name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k.Interface())
+			ft, ok := fields[key]
+			if !ok {
+				keys = append(keys, join(key))
+				continue
+			}
+			keys = append(keys, unknownKeys(v.MapIndex(k).Interface(), ft, join(key))...)
+		}
+	case v.Kind() == reflect.Map && t.Kind() == reflect.Map:
+		for _, k := range v.MapKeys() {
+			keys = append(keys, unknownKeys(v.MapIndex(k).Interface(), t.Elem(), join(fmt.Sprint(k.Interface())))...)
+		}
+	case v.Kind() == reflect.Slice && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for i := 0; i < v.Len(); i++ {
+			keys = append(keys, unknownKeys(v.Index(i).Interface(), t.Elem(), join(strconv.Itoa(i)))...)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func decodeGeneric(generic interface{}, v interface{}) error {
+	jsonStr, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonStr, v)
+}
+
+func typeErrorMsg(e *json.UnmarshalTypeError) string {
+	return fmt.Sprintf("cannot use %s as %s", e.Value, e.Type.String())
+}
+
+// lineColumn returns the line and column (starting at 1) of the offset.
+func lineColumn(src []byte, offset int) (int, int) {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	line := bytes.Count(src[:offset], []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(src[:offset], '\n')
+	return line, col
+}
+
+// findYAMLNode returns the node of the key path such as "label_rule.priority.0.level".
+func findYAMLNode(node *yaml.Node, path string) *yaml.Node {
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+
+	return node
+}
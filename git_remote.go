@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// githubHosts are hosts of github.com for https and ssh.
+var githubHosts = []string{"github.com", "www.github.com", "ssh.github.com"}
+
+// preferredRemotes are remote names used to infer the repository in the order of priority.
+// If none of them exists, the first remote in .git/config is used.
+var preferredRemotes = []string{"origin", "upstream"}
+
+// scpLikeURLPtn matches scp-like ssh urls such as "git@github.com:owner/repo.git".
+var scpLikeURLPtn = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// gitRemoteLayer returns the layer inferred from the git remote of the working directory.
+// It has username and repository, and base_url for GitHub Enterprise hosts of https remotes.
+// base_url is not inferred from ssh remotes, because the host may be an alias in ~/.ssh/config.
+func gitRemoteLayer() (configLayer, bool) {
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return configLayer{}, false
+	}
+
+	gitConfig := findGitConfig(wd)
+	if gitConfig == "" {
+		return configLayer{}, false
+	}
+
+	remotes, order, err := readGitRemotes(gitConfig)
+	if err != nil || len(order) == 0 {
+		return configLayer{}, false
+	}
+
+	name := order[0]
+	for _, v := range preferredRemotes {
+		if _, ok := remotes[v]; ok {
+			name = v
+			break
+		}
+	}
+
+	scheme, host, owner, repo, ok := parseRemoteURL(remotes[name])
+	if !ok {
+		return configLayer{}, false
+	}
+
+	values := map[string]interface{}{
+		"username":   owner,
+		"repository": repo,
+	}
+	if baseURL := remoteBaseURL(scheme, host); baseURL != "" {
+		values["base_url"] = baseURL
+	}
+
+	return configLayer{Source: fmt.Sprintf("git remote (%s)", name), Values: values}, true
+}
+
+// remoteBaseURL returns base_url of the api for GitHub Enterprise hosts of https remotes, or "" for others.
+// host can have the port.
+func remoteBaseURL(scheme, host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if scheme != "https" || existStr(githubHosts, strings.ToLower(hostname)) {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/api/v3/", host)
+}
+
+// findGitConfig returns the path of the git config file of the directory or its parents.
+func findGitConfig(dir string) string {
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitPath); err == nil {
+			if info.IsDir() {
+				return filepath.Join(gitPath, "config")
+			}
+			// worktrees and submodules have .git file which points to the git directory
+			return gitDirConfig(dir, gitPath)
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func gitDirConfig(dir, gitFile string) string {
+
+	content, err := ioutil.ReadFile(gitFile)
+	if err != nil {
+		return ""
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(content)), "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	// the config of worktrees is in the common directory
+	if common, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		gitDir = commonDir
+	}
+
+	return filepath.Join(gitDir, "config")
+}
+
+// readGitRemotes returns urls of remotes and the names in the order of appearance.
+func readGitRemotes(filename string) (map[string]string, []string, error) {
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	remotes := make(map[string]string)
+	order := []string{}
+	section := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = ""
+			header := strings.Trim(line, "[]")
+			if strings.HasPrefix(header, "remote ") {
+				section = strings.Trim(strings.TrimSpace(strings.TrimPrefix(header, "remote ")), `"`)
+			}
+			continue
+		}
+		if section == "" {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || strings.ToLower(strings.TrimSpace(kv[0])) != "url" {
+			continue
+		}
+		if _, ok := remotes[section]; !ok {
+			order = append(order, section)
+			remotes[section] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		}
+	}
+
+	return remotes, order, scanner.Err()
+}
+
+// parseRemoteURL returns the scheme, host with the port, owner and repository name of https, ssh, git and scp-like urls.
+// The scheme of scp-like urls is "ssh".
+func parseRemoteURL(rawURL string) (string, string, string, string, bool) {
+
+	var scheme, host, path string
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", "", "", false
+		}
+		scheme, host, path = strings.ToLower(u.Scheme), u.Host, u.Path
+	} else if m := scpLikeURLPtn.FindStringSubmatch(rawURL); m != nil {
+		scheme, host, path = "ssh", m[1], m[2]
+	} else {
+		return "", "", "", "", false
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/"), "/")
+	if host == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", "", false
+	}
+
+	return scheme, host, parts[0], parts[1], true
+}
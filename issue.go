@@ -1,12 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"sort"
-	"strconv"
+	"text/template"
+	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/urfave/cli"
@@ -22,7 +24,7 @@ func init() {
 		Name:  "issue",
 		Usage: "management related to issues or pull requests",
 		Action: func(c *cli.Context) error {
-			return action(c, &issue{Out: os.Stdout})
+			return action(c, &issue{Out: c.App.Writer, Err: os.Stderr})
 		},
 		Flags: []cli.Flag{
 			cli.BoolFlag{
@@ -33,41 +35,210 @@ func init() {
 				Name:  "priority, p",
 				Usage: "output priority list at the same time",
 			},
+			cli.StringFlag{
+				Name:  "team",
+				Value: "",
+				Usage: "output issues assigned to members of the team only",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "output format (text or html)",
+			},
+			cli.StringFlag{
+				Name:  "out, o",
+				Value: "-",
+				Usage: "output file (\"-\" means stdout)",
+			},
+			cli.BoolFlag{
+				Name:  "since-last",
+				Usage: "output changes since the last report",
+			},
+			cli.BoolFlag{
+				Name:  "no-snapshot",
+				Usage: "don't save a snapshot of issues for trend",
+			},
 		},
 	})
 }
 
 type issue struct {
 	Out io.Writer
+	Err io.Writer
 }
 
 func (i issue) Run(c *cli.Context, conf *config, client *github.Client) error {
 
+	format := c.String("format")
+	if format != "text" && format != "html" {
+		return fmt.Errorf("undefined output format (%s)", format)
+	}
+
+	tmplFile := ""
+	if conf.Template != nil {
+		tmplFile = *conf.Template
+	}
+	tmpl, err := readReportTemplate(tmplFile, conf.UserMappings)
+	if err != nil {
+		return err
+	}
+
 	issues, err := i.getAllIssues(client, *conf.User, *conf.Repo)
 	if err != nil {
 		return err
 	}
 
+	allIssues := issues
+	if team := c.String("team"); team != "" {
+		members, err := getTeamMembers(client, conf, team)
+		if err != nil {
+			return err
+		}
+		issues = i.filterIssuesByMembers(issues, members[team])
+	} else if names := conf.getPatternTeamNames(); len(names) > 0 {
+		// only for user mappings, so the report is output even if teams can't be resolved
+		if _, err := getTeamMembers(client, conf, names...); err != nil && i.Err != nil {
+			fmt.Fprintf(i.Err, "warning: unable to resolve team members (%s)\n", err)
+		}
+	}
+
 	exceptLabels := []string{}
 	if c.Bool("except") {
 		exceptLabels = conf.getLabels("Low")
 	}
 	priorityLabels := []string{}
-	if c.Bool("priority") {
+	if c.Bool("priority") || format == "html" {
 		priorityLabels = conf.getPriorityLabels("")
 	}
 
-	iInfo := i.createIssueInfo(issues, conf.getLabels("High"), exceptLabels, priorityLabels)
+	weights := issueWeights{
+		Priority: conf.getPriorityWeights(),
+		Size:     conf.getSizeWeights(),
+	}
+
+	iInfo := i.createIssueInfo(issues, conf.getLabels("High"), exceptLabels, priorityLabels, weights)
+
+	i.warnUnmappedUsers(iInfo.AssigneeRanking, conf.UserMappings)
+
+	if c.Bool("since-last") {
+		iInfo.Changes, err = i.getChanges(conf, c.String("team"), issues, allIssues)
+		if err != nil {
+			return err
+		}
+	}
+
+	message := ""
+	if conf.Message != nil {
+		message = *conf.Message
+	}
+
+	// fetch all data before the output, not to leave a broken output file on errors
+	var labels []*github.Label
+	if format == "html" {
+		labels, err = listLabels(client, *conf.User, *conf.Repo)
+		if err != nil {
+			return err
+		}
+	}
+
+	dest := c.String("out")
+	buf := &bytes.Buffer{}
+	if dest != "-" {
+		i.Out = buf
+	}
+
+	data := newReportData(iInfo, *conf.User, *conf.Repo, message, exceptLabels, priorityLabels)
+	if format == "html" {
+		err = i.outputHTML(i.Out, data, labels, conf.UserMappings)
+	} else {
+		err = i.outputResult(tmpl, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dest != "-" {
+		if err := writeFileAtomic(dest, buf.Bytes()); err != nil {
+			return fmt.Errorf("unable to create output file (%s)", dest)
+		}
+	}
 
-	i.outputResult(iInfo, *conf.User, *conf.Repo, *conf.Message, exceptLabels, priorityLabels, conf.UserMappings)
+	if !c.Bool("no-snapshot") {
+		i.saveSnapshot(conf, c.String("team"), issues, weights)
+	}
 
 	return nil
 }
 
+// getChanges returns the changes since the latest snapshot.
+// allIssues are the issues before filtering by team.
+func (i issue) getChanges(conf *config, team string, issues, allIssues []*github.Issue) (*issueChanges, error) {
+
+	store, err := newSnapshotStore(conf)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := store.Load(snapshotKey(conf, team))
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return &issueChanges{}, nil
+	}
+
+	return compareSnapshot(snapshots[len(snapshots)-1], issues, allIssues, conf.getPriorityLabels(""), conf.UserMappings), nil
+}
+
+// saveSnapshot saves a snapshot of the issues for trend.
+// Errors are output as warnings not to fail the report.
+func (i issue) saveSnapshot(conf *config, team string, issues []*github.Issue, weights issueWeights) {
+
+	iInfo := i.createIssueInfo(issues, conf.getLabels("High"), nil, conf.getPriorityLabels(""), weights)
+	snapshot := newIssueSnapshot(snapshotKey(conf, team), time.Now(), issues, iInfo)
+
+	store, err := newSnapshotStore(conf)
+	if err == nil {
+		err = store.Save(snapshot)
+	}
+	if err != nil && i.Err != nil {
+		fmt.Fprintf(i.Err, "warning: unable to save snapshot (%s)\n", err)
+	}
+}
+
+// filterIssuesByMembers returns issues assigned to at least one of the members.
+func (i issue) filterIssuesByMembers(baseIssues []*github.Issue, members []string) []*github.Issue {
+	issues := []*github.Issue{}
+	for _, issue := range baseIssues {
+		for _, user := range issue.Assignees {
+			if existStr(members, *user.Login) {
+				issues = append(issues, issue)
+				break
+			}
+		}
+	}
+	return issues
+}
+
+func (i issue) warnUnmappedUsers(assignees []string, userMap userMappings) {
+	if i.Err == nil || len(userMap) == 0 {
+		return
+	}
+	for _, v := range assignees {
+		if _, ok := userMap[v]; !ok {
+			fmt.Fprintf(i.Err, "warning: assignee is not found in user_mappings (%s)\n", v)
+		}
+	}
+}
+
 func (i issue) getAllIssues(client *github.Client, user, repo string) ([]*github.Issue, error) {
+	return i.listIssues(client, user, repo, "open")
+}
+
+// listIssues returns issues and pull requests in the state ("open", "closed" or "all").
+func (i issue) listIssues(client *github.Client, user, repo, state string) ([]*github.Issue, error) {
 
 	opt := &github.IssueListByRepoOptions{
-		State:     "open",
+		State:     state,
 		Sort:      "created",
 		Direction: "asc",
 		ListOptions: github.ListOptions{
@@ -92,10 +263,11 @@ func (i issue) getAllIssues(client *github.Client, user, repo string) ([]*github
 	return allIssues, nil
 }
 
-func (i issue) createIssueInfo(baseIssues []*github.Issue, highLabels, exceptLabels, priorityLabels []string) issueInfo {
+func (i issue) createIssueInfo(baseIssues []*github.Issue, highLabels, exceptLabels, priorityLabels []string, weights issueWeights) issueInfo {
 
 	issueAssignees := make(map[int][]string)
 	assigneeIssues := make(map[string][]int)
+	assigneeScores := make(map[string]float64)
 	assignees := []string{}
 	priorityIssues := make(map[string][]int)
 	highIssues := []int{}
@@ -114,11 +286,13 @@ ISSUE_LOOP:
 			}
 		}
 
-		// set issueAssignees, assigneeIssues and assignees
+		// set issueAssignees, assigneeIssues, assigneeScores and assignees
 		issueAssignees[*issue.Number] = []string{}
+		weight := weights.getWeight(issue)
 		if len(issue.Assignees) > 0 {
 			for _, user := range issue.Assignees {
 				issueAssignees[*issue.Number] = append(issueAssignees[*issue.Number], *user.Login)
+				assigneeScores[*user.Login] += weight
 				if _, ok := assigneeIssues[*user.Login]; ok {
 					assigneeIssues[*user.Login] = append(assigneeIssues[*user.Login], *issue.Number)
 				} else {
@@ -127,6 +301,7 @@ ISSUE_LOOP:
 				}
 			}
 		} else {
+			assigneeScores[noAssigneesLabel] += weight
 			if _, ok := assigneeIssues[noAssigneesLabel]; ok {
 				assigneeIssues[noAssigneesLabel] = append(assigneeIssues[noAssigneesLabel], *issue.Number)
 			} else {
@@ -134,17 +309,18 @@ ISSUE_LOOP:
 			}
 		}
 
-		// set priorityIssues
+		// set priorityIssues (only the highest priority label is used)
 		existPriority := false
 		if len(priorityLabels) > 0 {
+			issueLabels := []string{}
 			for _, label := range issue.Labels {
-				if existStr(priorityLabels, *label.Name) {
-					if _, ok := priorityIssues[*label.Name]; ok {
-						priorityIssues[*label.Name] = append(priorityIssues[*label.Name], *issue.Number)
-					} else {
-						priorityIssues[*label.Name] = []int{*issue.Number}
-					}
+				issueLabels = append(issueLabels, *label.Name)
+			}
+			for _, v := range priorityLabels {
+				if existStr(issueLabels, v) {
+					priorityIssues[v] = append(priorityIssues[v], *issue.Number)
 					existPriority = true
+					break
 				}
 			}
 			if !existPriority {
@@ -168,7 +344,10 @@ ISSUE_LOOP:
 	}
 
 	// sort
-	sort.Slice(assignees, func(i, j int) bool {
+	sort.SliceStable(assignees, func(i, j int) bool {
+		if assigneeScores[assignees[i]] != assigneeScores[assignees[j]] {
+			return assigneeScores[assignees[j]] < assigneeScores[assignees[i]]
+		}
 		return len(assigneeIssues[assignees[j]]) < len(assigneeIssues[assignees[i]])
 	})
 	sort.Ints(highIssues)
@@ -177,6 +356,7 @@ ISSUE_LOOP:
 		BaseIssues:      baseIssues,
 		IssueAssignees:  issueAssignees,
 		AssigneeIssues:  assigneeIssues,
+		AssigneeScores:  assigneeScores,
 		AssigneeRanking: assignees,
 		PriorityIssues:  priorityIssues,
 		HighIssues:      highIssues,
@@ -184,92 +364,48 @@ ISSUE_LOOP:
 	}
 }
 
-func (i issue) outputResult(iInfo issueInfo, user, repo, message string,
-	exceptLabels, priorityLabels []string, userMap userMappings) {
-
+// outputResult renders the report with the template.
+func (i issue) outputResult(tmpl *template.Template, data reportData) error {
 	if i.Out == nil {
-		return
-	}
-
-	// prepare
-	maxAssigneeLen := 0
-	if _, ok := iInfo.AssigneeIssues[noAssigneesLabel]; ok {
-		maxAssigneeLen = len(noAssigneesLabel)
-	}
-	for _, v := range iInfo.AssigneeRanking {
-		if maxAssigneeLen < len(v) {
-			maxAssigneeLen = len(v)
-		}
-	}
-
-	maxPriorityLen := len(strconv.Itoa(*iInfo.BaseIssues[len(iInfo.BaseIssues)-1].Number))
-
-	// output
-	fmt.Fprintf(i.Out, "# Issue & PR List for `%s/%s`\n", user, repo)
-
-	fmt.Fprintf(i.Out, "\ttask count: %d\n", len(iInfo.BaseIssues)-iInfo.ExceptIssueCnt)
-	fmt.Fprintf(i.Out, "\turgent: %s\n", nvl(concatInt(iInfo.HighIssues, ", ")))
-	if len(exceptLabels) > 0 {
-		fmt.Fprintf(i.Out, "\texcepts labels: %s\n", nvl(concatStrWithBracket(exceptLabels, ", ", "`")))
-	}
-	// Assingee List
-	if len(priorityLabels) > 0 {
-		fmt.Fprintln(i.Out, "\n*Assingee List*\n```")
-	}
-	for _, v := range iInfo.AssigneeRanking {
-		fmt.Fprint(i.Out, i.assigneeLine(userMap.getValue(v), iInfo.AssigneeIssues[v], maxAssigneeLen))
-	}
-	if _, ok := iInfo.AssigneeIssues[noAssigneesLabel]; ok {
-		fmt.Fprint(i.Out, i.assigneeLine(noAssigneesLabel, iInfo.AssigneeIssues[noAssigneesLabel], maxAssigneeLen))
-	}
-	fmt.Fprintln(i.Out, "```")
-
-	// Priority List
-	if len(priorityLabels) > 0 {
-		fmt.Fprintln(i.Out, "\n*Priority List*\n```")
-		for _, v := range priorityLabels {
-			if _, ok := iInfo.PriorityIssues[v]; !ok {
-				continue
-			}
-			fmt.Fprintf(i.Out, "- %s\n", v)
-			fmt.Fprint(i.Out, i.priorityLines(iInfo.PriorityIssues[v], maxPriorityLen, iInfo.IssueAssignees, userMap))
-		}
-		if _, ok := iInfo.PriorityIssues[noPriorityLabel]; ok {
-			fmt.Fprintf(i.Out, "- %s\n", noPriorityLabel)
-			fmt.Fprint(i.Out, i.priorityLines(iInfo.PriorityIssues[noPriorityLabel], maxPriorityLen, iInfo.IssueAssignees, userMap))
-		}
-		fmt.Fprintln(i.Out, "```")
-	}
-
-	fmt.Fprintf(i.Out, "\n%s\n", concatStrWith2Brackets(userMap.getValues(iInfo.AssigneeRanking), ", ", "@", ""))
-	if message != "" {
-		fmt.Fprintln(i.Out, message)
+		return nil
 	}
-}
-
-func (i issue) assigneeLine(name string, issues []int, maxLen int) string {
-	return fmt.Sprintf("- %s%s (%d): %s\n", name, space(maxLen-len(name)), len(issues), concatInt(issues, ", "))
-}
-
-func (i issue) priorityLines(issues []int, maxLen int, issueAssignees map[int][]string, userMap userMappings) string {
-	str := ""
-	for _, v := range issues {
-		assignees := concatStr(userMap.getValues(issueAssignees[v]), ", ")
-		if assignees == "" {
-			assignees = noAssigneesLabel
-		}
-		str += fmt.Sprintf("  - %d%s: %s\n", v, space(maxLen-len(strconv.Itoa(v))), assignees)
-	}
-
-	return str
+	return tmpl.Execute(i.Out, data)
 }
 
 type issueInfo struct {
 	BaseIssues      []*github.Issue
 	IssueAssignees  map[int][]string
 	AssigneeIssues  map[string][]int
+	AssigneeScores  map[string]float64
 	AssigneeRanking []string
 	PriorityIssues  map[string][]int
 	HighIssues      []int
 	ExceptIssueCnt  int
+	Changes         *issueChanges
+}
+
+// issueWeights is used to calculate the weighted load of each issue.
+// Labels not found in these maps are counted as weight 1.
+type issueWeights struct {
+	Priority map[string]float64
+	Size     map[string]float64
+}
+
+func (w issueWeights) getWeight(issue *github.Issue) float64 {
+	priority, size := 0.0, 0.0
+	for _, label := range issue.Labels {
+		if v, ok := w.Priority[*label.Name]; ok && priority < v {
+			priority = v
+		}
+		if v, ok := w.Size[*label.Name]; ok && size < v {
+			size = v
+		}
+	}
+	if priority == 0 {
+		priority = 1
+	}
+	if size == 0 {
+		size = 1
+	}
+	return priority * size
 }
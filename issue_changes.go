@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// issueChanges is the difference between the previous report and the current issues.
+// Since is zero if there is no previous report.
+type issueChanges struct {
+	Since         time.Time
+	NewIssues     []int
+	ClosedIssues  []int
+	Reassigned    []issueChange
+	ReassignedOut []issueChange
+	Reprioritized []issueChange
+}
+
+type issueChange struct {
+	Number        int
+	Before, After string
+}
+
+// compareSnapshot returns the changes from the snapshot to the issues.
+// allIssues are the current issues before filtering by team, and issues which are in allIssues but not in issues
+// are regarded as reassigned out of the team. Issues which are not in allIssues are regarded as closed.
+func compareSnapshot(prev issueSnapshot, issues, allIssues []*github.Issue, priorityLabels []string, userMap userMappings) *issueChanges {
+
+	changes := &issueChanges{
+		Since:         prev.Time,
+		NewIssues:     []int{},
+		ClosedIssues:  []int{},
+		Reassigned:    []issueChange{},
+		ReassignedOut: []issueChange{},
+		Reprioritized: []issueChange{},
+	}
+
+	prevIssues := make(map[int]snapshotIssue)
+	for _, v := range prev.Issues {
+		prevIssues[v.Number] = v
+	}
+
+	current := newIssueSnapshot(prev.Key, time.Now(), issues, issueInfo{})
+	currentNums := []int{}
+	for _, v := range current.Issues {
+		currentNums = append(currentNums, v.Number)
+		p, ok := prevIssues[v.Number]
+		if !ok {
+			changes.NewIssues = append(changes.NewIssues, v.Number)
+			continue
+		}
+		before, after := snapshotAssignees(p, userMap), snapshotAssignees(v, userMap)
+		if before != after {
+			changes.Reassigned = append(changes.Reassigned, issueChange{Number: v.Number, Before: before, After: after})
+		}
+		before, after = snapshotPriority(p, priorityLabels), snapshotPriority(v, priorityLabels)
+		if before != after {
+			changes.Reprioritized = append(changes.Reprioritized, issueChange{Number: v.Number, Before: before, After: after})
+		}
+	}
+	allIssueMap := make(map[int]snapshotIssue)
+	for _, v := range newIssueSnapshot(prev.Key, time.Now(), allIssues, issueInfo{}).Issues {
+		allIssueMap[v.Number] = v
+	}
+	for _, v := range prev.Issues {
+		if existInt(currentNums, v.Number) {
+			continue
+		}
+		if a, ok := allIssueMap[v.Number]; ok {
+			changes.ReassignedOut = append(changes.ReassignedOut, issueChange{Number: v.Number, Before: snapshotAssignees(v, userMap), After: snapshotAssignees(a, userMap)})
+		} else {
+			changes.ClosedIssues = append(changes.ClosedIssues, v.Number)
+		}
+	}
+
+	sort.Ints(changes.NewIssues)
+	sort.Ints(changes.ClosedIssues)
+	sort.Slice(changes.Reassigned, func(i, j int) bool { return changes.Reassigned[i].Number < changes.Reassigned[j].Number })
+	sort.Slice(changes.ReassignedOut, func(i, j int) bool { return changes.ReassignedOut[i].Number < changes.ReassignedOut[j].Number })
+	sort.Slice(changes.Reprioritized, func(i, j int) bool { return changes.Reprioritized[i].Number < changes.Reprioritized[j].Number })
+
+	return changes
+}
+
+func snapshotAssignees(is snapshotIssue, userMap userMappings) string {
+	if len(is.Assignees) == 0 {
+		return noAssigneesLabel
+	}
+	names := userMap.getValues(is.Assignees)
+	sort.Strings(names)
+	return concatStr(names, ", ")
+}
+
+// snapshotPriority returns the first priority label of the issue in the order of label_rule.
+func snapshotPriority(is snapshotIssue, priorityLabels []string) string {
+	for _, v := range priorityLabels {
+		if existStr(is.Labels, v) {
+			return v
+		}
+	}
+	return noPriorityLabel
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestCompareSnapshot(t *testing.T) {
+
+	prev := issueSnapshot{
+		Key:  "octo-org/octo-repo#team-a",
+		Time: time.Date(2018, 9, 13, 9, 0, 0, 0, time.UTC),
+		Issues: []snapshotIssue{
+			{Number: 1, Labels: []string{"urgent"}, Assignees: []string{"alice"}},
+			{Number: 2, Labels: []string{"minor"}, Assignees: []string{"bob"}},
+			{Number: 3, Labels: []string{}, Assignees: []string{"alice"}},
+			{Number: 4, Labels: []string{"major"}, Assignees: []string{"bob"}},
+			{Number: 5, Labels: []string{"major"}, Assignees: []string{"alice", "bob"}},
+		},
+	}
+
+	// team-a is alice and bob, and 4 is reassigned to carol out of the team
+	allIssues := []*github.Issue{
+		newTestIssue(1, []string{"urgent"}, []string{"alice"}),
+		newTestIssue(2, []string{"major"}, []string{"alice"}),
+		newTestIssue(4, []string{"major"}, []string{"carol"}),
+		newTestIssue(5, []string{"major"}, []string{"bob", "alice"}),
+		newTestIssue(6, nil, []string{"bob"}),
+		newTestIssue(7, nil, []string{"carol"}),
+	}
+	issues := issue{}.filterIssuesByMembers(allIssues, []string{"alice", "bob"})
+
+	userMap := userMappings{"alice": "alice.slack"}
+	changes := compareSnapshot(prev, issues, allIssues, []string{"urgent", "major", "minor"}, userMap)
+
+	expected := &issueChanges{
+		Since:         prev.Time,
+		NewIssues:     []int{6},
+		ClosedIssues:  []int{3},
+		Reassigned:    []issueChange{{Number: 2, Before: "bob", After: "alice.slack"}},
+		ReassignedOut: []issueChange{{Number: 4, Before: "bob", After: "carol"}},
+		Reprioritized: []issueChange{{Number: 2, Before: "minor", After: "major"}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("changes are %+v, expected %+v", changes, expected)
+	}
+
+	// without team, all issues which are not in the current issues are closed
+	changes = compareSnapshot(prev, allIssues, allIssues, []string{"urgent", "major", "minor"}, userMap)
+	if expected := []int{3}; !reflect.DeepEqual(changes.ClosedIssues, expected) || len(changes.ReassignedOut) != 0 {
+		t.Errorf("closed issues are %v and reassigned out are %v, expected %v and none", changes.ClosedIssues, changes.ReassignedOut, expected)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestIssueReplay(t *testing.T) {
+
+	expected := `# Issue & PR List for ` + "`octo-org/octo-repo`" + `
+	task count: 5
+	urgent: 1
+
+*Assingee List*
+` + "```" + `
+- alice.slack    (2, score=17): 1, 5
+- bob.slack      (2, score=4): 2, 5
+- (No Assignees) (2, score=3): 3, 4
+` + "```" + `
+
+*Priority List*
+` + "```" + `
+- urgent
+  - 1: alice.slack
+- major
+  - 3: (No Assignees)
+  - 5: alice.slack, bob.slack
+- minor
+  - 2: bob.slack
+- (No Priority Labels)
+  - 4: (No Assignees)
+` + "```" + `
+
+@alice.slack, @bob.slack
+Please check the assigned issues.
+`
+
+	if out := runReplay(t, "issue", "--no-snapshot", "--priority"); out != expected {
+		t.Errorf("output is\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestIssueReplayHTML(t *testing.T) {
+
+	out := runReplay(t, "issue", "--no-snapshot", "--format", "html")
+
+	for _, v := range []string{
+		"<title>Issue &amp; PR List for octo-org/octo-repo</title>",
+		`<a href="https://github.com/octo-org/octo-repo/issues/1" title="Crash on startup">#1</a>`,
+		// colors of the repository labels
+		`<span class="label" style="background-color: #ff3000; color: #ffffff">urgent</span>`,
+		`<span class="label" style="background-color: #ededed; color: #000000">size/S</span>`,
+		"<td>alice.slack bob.slack </td>",
+	} {
+		if !strings.Contains(out, v) {
+			t.Errorf("output doesn't contain %q", v)
+		}
+	}
+}
+
+func TestIssueReplayOut(t *testing.T) {
+
+	tmpDir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dest := filepath.Join(tmpDir, "out", "report.html")
+	if err := os.Mkdir(filepath.Dir(dest), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out := runReplay(t, "issue", "--no-snapshot", "--format", "html", "--out", dest); out != "" {
+		t.Errorf("report is output to stdout: %q", out)
+	}
+	src, err := ioutil.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("output file is not created: %s", err)
+	}
+	if !strings.Contains(string(src), "<title>Issue &amp; PR List for octo-org/octo-repo</title>") {
+		t.Errorf("output file doesn't contain the report: %q", src)
+	}
+	if files, _ := filepath.Glob(filepath.Join(filepath.Dir(dest), "*")); len(files) != 1 {
+		t.Errorf("temporary files are left: %v", files)
+	}
+
+	// the output file is not created if the labels can't be fetched
+	dir := filepath.Join(tmpDir, "replay")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(fixtureDir, "GET_*_issues_*.json"))
+	for _, f := range files {
+		src, _ := ioutil.ReadFile(f)
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(f)), src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dest = filepath.Join(tmpDir, "report.html")
+	app := newApp()
+	app.Writer = &bytes.Buffer{}
+	if err := app.Run([]string{"githubmgr", "--config", "testdata/config.json", "--replay", dir, "issue", "--no-snapshot", "--format", "html", "--out", dest}); err == nil {
+		t.Error("issue command doesn't return error without the labels")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("output file is created on error: %v", err)
+	}
+}
+
+func newTestIssue(num int, labels []string, assignees []string) *github.Issue {
+	is := &github.Issue{Number: github.Int(num)}
+	for _, v := range labels {
+		is.Labels = append(is.Labels, github.Label{Name: github.String(v)})
+	}
+	for _, v := range assignees {
+		is.Assignees = append(is.Assignees, &github.User{Login: github.String(v)})
+	}
+	return is
+}
+
+func TestIssueWeight(t *testing.T) {
+
+	weights := issueWeights{
+		Priority: map[string]float64{"urgent": 5, "major": 2, "pending": 1},
+		Size:     map[string]float64{"size/S": 1, "size/L": 3},
+	}
+
+	cases := []struct {
+		labels   []string
+		expected float64
+	}{
+		{nil, 1},
+		{[]string{"bug"}, 1},
+		{[]string{"major"}, 2},
+		{[]string{"size/L"}, 3},
+		{[]string{"urgent", "size/L"}, 15},
+		// the highest weight is used if there are several labels
+		{[]string{"pending", "urgent", "major"}, 5},
+		{[]string{"major", "size/S", "size/L"}, 6},
+	}
+
+	for _, c := range cases {
+		if w := weights.getWeight(newTestIssue(1, c.labels, nil)); w != c.expected {
+			t.Errorf("%v: weight is %g, expected %g", c.labels, w, c.expected)
+		}
+	}
+}
+
+func TestIssueCreateIssueInfo(t *testing.T) {
+
+	issues := []*github.Issue{
+		newTestIssue(1, []string{"urgent", "size/L"}, []string{"alice"}),
+		newTestIssue(2, []string{"minor"}, []string{"bob"}),
+		newTestIssue(3, []string{"major"}, []string{"alice", "bob"}),
+		newTestIssue(4, []string{"wontfix"}, nil),
+		newTestIssue(5, nil, []string{"carol"}),
+		newTestIssue(6, []string{"minor"}, []string{"carol"}),
+	}
+	weights := issueWeights{
+		Priority: map[string]float64{"urgent": 5, "major": 2, "minor": 2, "wontfix": 1},
+		Size:     map[string]float64{"size/L": 3},
+	}
+
+	iInfo := issue{}.createIssueInfo(issues, []string{"urgent"}, []string{"wontfix"}, []string{"urgent", "major", "minor"}, weights)
+
+	expectedScores := map[string]float64{"alice": 17, "bob": 4, "carol": 3}
+	if !reflect.DeepEqual(iInfo.AssigneeScores, expectedScores) {
+		t.Errorf("scores are %v, expected %v", iInfo.AssigneeScores, expectedScores)
+	}
+	// sorted by score, and by number of issues if scores are the same
+	if expected := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(iInfo.AssigneeRanking, expected) {
+		t.Errorf("ranking is %v, expected %v", iInfo.AssigneeRanking, expected)
+	}
+	if expected := []int{1, 3}; !reflect.DeepEqual(iInfo.AssigneeIssues["alice"], expected) {
+		t.Errorf("issues of alice are %v, expected %v", iInfo.AssigneeIssues["alice"], expected)
+	}
+	if iInfo.ExceptIssueCnt != 1 {
+		t.Errorf("except issue count is %d, expected 1", iInfo.ExceptIssueCnt)
+	}
+	if expected := []int{1}; !reflect.DeepEqual(iInfo.HighIssues, expected) {
+		t.Errorf("high issues are %v, expected %v", iInfo.HighIssues, expected)
+	}
+	expectedPriorities := map[string][]int{"urgent": {1}, "minor": {2, 6}, "major": {3}, noPriorityLabel: {5}}
+	if !reflect.DeepEqual(iInfo.PriorityIssues, expectedPriorities) {
+		t.Errorf("priority issues are %v, expected %v", iInfo.PriorityIssues, expectedPriorities)
+	}
+}
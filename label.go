@@ -2,10 +2,8 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
@@ -19,7 +17,7 @@ func init() {
 		Name:  "label",
 		Usage: "check or set up label settings with json file",
 		Action: func(c *cli.Context) error {
-			return action(c, &label{Out: os.Stdout})
+			return action(c, &label{In: os.Stdin, Out: c.App.Writer})
 		},
 		Flags: []cli.Flag{
 			cli.StringFlag{
@@ -27,30 +25,100 @@ func init() {
 				Value: "label_settings.json",
 				Usage: "you can change label setting json file",
 			},
+			cli.BoolFlag{
+				Name:  "repo-settings, r",
+				Usage: "read label settings from " + repoSettingPath + " in the default branch of the repository",
+			},
 			cli.BoolFlag{
 				Name:  "update, u",
 				Usage: "if this option is set, send update request to github",
 			},
+			cli.StringFlag{
+				Name:  "strategy, s",
+				Usage: "strategy for labels to delete which are attached to issues (strip, migrate-to:<label> or keep)",
+			},
+			cli.BoolFlag{
+				Name:  "interactive, i",
+				Usage: "review each operation and send update request of the selected operations only",
+			},
+		},
+		Subcommands: []cli.Command{
+			labelLintCmd(),
+			labelSyncCmd(),
+			labelPreviewCmd(),
+			labelPlanCmd(),
+			labelApplyCmd(),
+			labelStatsCmd(),
 		},
 	})
 }
 
 type label struct {
+	In  io.Reader
 	Out io.Writer
 }
 
+// repoSettingPath is the path of the label settings stored in the repository.
+const repoSettingPath = ".github/labels.json"
+
+// labelSettingFile returns the setting file specified by the flags.
+func labelSettingFile(c *cli.Context, conf *config) string {
+	if c.Bool("repo-settings") {
+		return fmt.Sprintf("%s%s/%s/%s", remoteSettingPrefix, *conf.User, *conf.Repo, repoSettingPath)
+	}
+	return c.String("file")
+}
+
 func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 
-	setting, err := l.ReadSettings(c.String("file"))
+	setting, err := l.ReadSettings(labelSettingFile(c, conf), client)
 	if err != nil {
 		return err
 	}
 
-	labels, _, err := client.Issues.ListLabels(context.Background(), *conf.User, *conf.Repo, nil)
-	if err != nil {
+	return l.apply(c, conf, client, setting)
+}
+
+// apply outputs the difference between the settings and the repository labels,
+// and updates the labels if the update flag is set.
+func (l label) apply(c *cli.Context, conf *config, client *github.Client, setting *labelSetting) error {
+
+	updOpes, ok, err := l.plan(conf, client, setting, c.String("strategy"))
+	if err != nil || !ok {
 		return err
 	}
 
+	if c.Bool("interactive") {
+		updOpes, ok, err = labelReview{In: l.In, Out: l.Out}.Review(updOpes, setting)
+		if err != nil || !ok {
+			return err
+		}
+	} else if !c.Bool("update") {
+		return nil
+	}
+
+	l.execute(conf, client, updOpes)
+
+	return nil
+}
+
+// plan outputs the difference between the settings and the repository labels,
+// and returns the operations to apply the settings.
+// defaultStrategy is used for labels to delete which are attached to issues and don't match `on_delete`.
+// ok is false if the settings can't be applied.
+func (l label) plan(conf *config, client *github.Client, setting *labelSetting, defaultStrategy string) (updOpes []updOpe, ok bool, err error) {
+
+	if defaultStrategy != "" {
+		if err := setting.checkDeleteStrategy(defaultStrategy); err != nil {
+			return nil, false, err
+		}
+	}
+
+	labels, err := listLabels(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return nil, false, err
+	}
+
 	currentLabelMap := make(map[string]*struct {
 		Color     string
 		IsDefined bool
@@ -62,7 +130,7 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 		}{*v.Color, false}
 	}
 
-	updOpes := []updOpe{}
+	updOpes = []updOpe{}
 
 	// output
 	fmt.Fprintf(l.Out, "# Label settings for `%s/%s`\n", *conf.User, *conf.Repo)
@@ -72,7 +140,9 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 		for _, v := range setting.Labels {
 			if cl, ok := currentLabelMap[v.Name]; ok {
 				cl.IsDefined = true
-				updOpes = append(updOpes, l.CreateUpdOpe(v.Name, opeUpd, v.Color, v.Desc, nil))
+				updOpe := l.CreateUpdOpe(v.Name, opeUpd, v.Color, v.Desc, nil)
+				updOpe.CurrentColor = cl.Color
+				updOpes = append(updOpes, updOpe)
 				fmt.Fprintf(l.Out, "    `%s`: update (color=\"%s\" -> \"%s\", desc=\"%s\")\n", v.Name, cl.Color, v.Color, v.Desc)
 			} else {
 				updOpes = append(updOpes, l.CreateUpdOpe(v.Name, opeCrt, v.Color, v.Desc, nil))
@@ -89,7 +159,7 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 				cl.IsDefined = true
 				issueNums, err := l.GetIssues(client, *conf.User, *conf.Repo, v.From)
 				if err != nil {
-					return err
+					return nil, false, err
 				}
 				if len(issueNums) > 0 {
 					updOpes = append(updOpes, l.CreateUpdOpe(v.To, opeIss, "", "", issueNums))
@@ -141,11 +211,24 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 		existDelLabel = true
 		issueNums, err := l.GetIssues(client, *conf.User, *conf.Repo, k)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 		if len(issueNums) > 0 {
-			existDelLabelWithIssue = true
-			fmt.Fprintf(l.Out, "    `%s` (issues=%s)\n", k, concatInt(issueNums, ", "))
+			kind, target, _ := parseDeleteStrategy(setting.deleteStrategy(k, defaultStrategy))
+			switch kind {
+			case deleteStrip:
+				updOpes = append(updOpes, l.CreateUpdOpe(k, opeDel, "", "", issueNums))
+				fmt.Fprintf(l.Out, "    `%s`: strip from issues (issues=%s) and delete\n", k, concatInt(issueNums, ", "))
+			case deleteMigrateTo:
+				updOpes = append(updOpes, l.CreateUpdOpe(target, opeIss, "", "", issueNums))
+				updOpes = append(updOpes, l.CreateUpdOpe(k, opeDel, "", "", nil))
+				fmt.Fprintf(l.Out, "    `%s`: migrate to `%s` (issues=%s) and delete\n", k, target, concatInt(issueNums, ", "))
+			case deleteKeep:
+				fmt.Fprintf(l.Out, "    `%s`: keep (issues=%s)\n", k, concatInt(issueNums, ", "))
+			default:
+				existDelLabelWithIssue = true
+				fmt.Fprintf(l.Out, "    `%s` (issues=%s)\n", k, concatInt(issueNums, ", "))
+			}
 		} else {
 			updOpes = append(updOpes, l.CreateUpdOpe(k, opeDel, "", "", nil))
 			fmt.Fprintf(l.Out, "    `%s`\n", k)
@@ -159,13 +242,23 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 	if existDelLabelWithIssue {
 		fmt.Fprintln(l.Out, "  There is a label attached to issues in the delete labels.")
 		fmt.Fprintln(l.Out, "  Please dettatch it from issues or write a label settings.")
-		return nil
+		fmt.Fprintln(l.Out, "  (you can also set `on_delete` in the label settings or --strategy option)")
+		return nil, false, nil
 	}
 
-	if !c.Bool("update") {
-		return nil
+	for i, v := range updOpes {
+		if v.Operation == opeDel {
+			updOpes[i].CurrentColor = currentLabelMap[v.Name].Color
+		}
 	}
 
+	return updOpes, true, nil
+}
+
+// execute sends the update requests of the operations to github.
+func (l label) execute(conf *config, client *github.Client, updOpes []updOpe) {
+
+	var err error
 	fmt.Fprintln(l.Out, "  Update in progress...")
 	for _, uOpe := range updOpes {
 		// TODO github.Label doesn't have Description in v15.0.0, so don't set it...
@@ -204,22 +297,39 @@ func (l label) Run(c *cli.Context, conf *config, client *github.Client) error {
 			panic(fmt.Sprintf("undefine operation string \"%s\"", uOpe.Operation))
 		}
 	}
-
-	return nil
 }
 
 type labelSetting struct {
-	Labels []struct {
-		Name  string `json:"name"`
-		Color string `json:"color"`
-		Desc  string `json:"desc"`
-	} `json:"labels"`
+	Labels  []labelDef `json:"labels"`
 	Replace []struct {
 		From string `json:"from"`
 		To   string `json:"to"`
 	} `json:"replace"`
-	Ignore   []string `json:"ignore"`
-	LabelMap map[string]labelItem
+	Extend          []string `json:"extend"`
+	Ignore          []string `json:"ignore"`
+	ExclusiveGroups []struct {
+		Name   string   `json:"name"`
+		Labels []string `json:"labels"`
+	} `json:"exclusive_groups"`
+	OnDelete []struct {
+		Label    string `json:"label"`
+		Strategy string `json:"strategy"`
+	} `json:"on_delete"`
+	Palette      map[string]string `json:"palette"`
+	ColorSchemes []struct {
+		Name   string   `json:"name"`
+		Labels []string `json:"labels"`
+		From   string   `json:"from"`
+		To     string   `json:"to"`
+	} `json:"color_schemes"`
+	MinContrast *float64 `json:"min_contrast"`
+	LabelMap    map[string]labelItem
+}
+
+type labelDef struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Desc  string `json:"desc"`
 }
 
 type labelItem struct {
@@ -227,18 +337,28 @@ type labelItem struct {
 	IsIgnore               bool
 }
 
-func (l label) ReadSettings(filename string) (*labelSetting, error) {
+// ReadSettings reads the setting file and the settings it extends.
+// filename can be a file in another repository such as "github:owner/repo/path@ref".
+// client is used to fetch settings in other repositories, and can be nil if there are no such settings.
+func (l label) ReadSettings(filename string, client *github.Client) (*labelSetting, error) {
 
-	setting := &labelSetting{}
-
-	jsonStr, err := ioutil.ReadFile(filename)
+	ref, err := settingRef{}.resolve(filename)
 	if err != nil {
-		return nil, fmt.Errorf("not found config file (%s)", filename)
+		return nil, err
+	}
+	if ref.Owner == "" {
+		if _, err := os.Stat(filename); err != nil {
+			return nil, fmt.Errorf("not found config file (%s)", filename)
+		}
 	}
 
-	err = json.Unmarshal(jsonStr, setting)
+	setting, err := loadSettings(ref, client, make(map[string]bool))
 	if err != nil {
-		return nil, fmt.Errorf("something wrong in setting file (%s)", filename)
+		return nil, err
+	}
+
+	if err := setting.resolveColors(); err != nil {
+		return nil, err
 	}
 
 	// create LabelMap and check
@@ -271,9 +391,112 @@ func (l label) ReadSettings(filename string) (*labelSetting, error) {
 		setting.LabelMap[v2] = labelItem{IsIgnore: true}
 	}
 
+	grouped := make(map[string]bool)
+	for _, g := range setting.ExclusiveGroups {
+		for _, v := range g.Labels {
+			if item, ok := setting.LabelMap[v]; !ok || item.ReplaceTo != "" || item.IsIgnore {
+				return nil, fmt.Errorf("label name of `exclusive_groups` is not found in labels (%s)", v)
+			}
+			if grouped[v] {
+				return nil, fmt.Errorf("label name is duplicated in exclusive groups (%s)", v)
+			}
+			grouped[v] = true
+		}
+	}
+
+	for _, v := range setting.OnDelete {
+		if err := setting.checkDeleteStrategy(v.Strategy); err != nil {
+			return nil, err
+		}
+	}
+
 	return setting, nil
 }
 
+var labelColorPtn = regexp.MustCompile("^[0-9a-fA-F]{6}$")
+
+// validate returns problems in the settings such as invalid colors,
+// in the form of "key path: message".
+func (s *labelSetting) validate() []string {
+
+	problems := []string{}
+	addProblem := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	for i, v := range s.Labels {
+		if v.Name == "" {
+			addProblem("labels.%d.name: missing field", i)
+		}
+		if v.Color != "" && !isColorValue(v.Color) {
+			addProblem("labels.%d.color: invalid color, must be 6 hex digits or palette name (%s)", i, v.Color)
+		}
+	}
+	for i, v := range s.Replace {
+		if v.From == "" {
+			addProblem("replace.%d.from: missing field", i)
+		}
+		if v.To == "" {
+			addProblem("replace.%d.to: missing field", i)
+		}
+	}
+	for i, v := range s.Ignore {
+		if _, err := regexp.Compile("^" + strings.Replace(v, "*", ".*", -1) + "$"); err != nil {
+			addProblem("ignore.%d: invalid pattern (%s)", i, v)
+		}
+	}
+	for i, v := range s.ExclusiveGroups {
+		if v.Name == "" {
+			addProblem("exclusive_groups.%d.name: missing field", i)
+		}
+	}
+	for _, k := range paletteNames(s.Palette) {
+		v := s.Palette[k]
+		if !paletteNamePtn.MatchString(k) {
+			addProblem("palette.%s: invalid palette name", k)
+		}
+		if !labelColorPtn.MatchString(v) {
+			addProblem("palette.%s: invalid color, must be 6 hex digits (%s)", k, v)
+		}
+	}
+	for i, v := range s.ColorSchemes {
+		if v.Name == "" {
+			addProblem("color_schemes.%d.name: missing field", i)
+		}
+		if !isColorValue(v.From) {
+			addProblem("color_schemes.%d.from: invalid color, must be 6 hex digits or palette name (%s)", i, v.From)
+		}
+		if !isColorValue(v.To) {
+			addProblem("color_schemes.%d.to: invalid color, must be 6 hex digits or palette name (%s)", i, v.To)
+		}
+	}
+	for i, v := range s.OnDelete {
+		if v.Label == "" {
+			addProblem("on_delete.%d.label: missing field", i)
+		} else if _, err := regexp.Compile("^" + strings.Replace(v.Label, "*", ".*", -1) + "$"); err != nil {
+			addProblem("on_delete.%d.label: invalid pattern (%s)", i, v.Label)
+		}
+		if _, _, err := parseDeleteStrategy(v.Strategy); err != nil {
+			addProblem("on_delete.%d.strategy: %s", i, err)
+		}
+	}
+	if s.MinContrast != nil && (*s.MinContrast < 1 || *s.MinContrast > 21) {
+		addProblem("min_contrast: must be between 1 and 21 (%g)", *s.MinContrast)
+	}
+
+	return problems
+}
+
+// isIgnored reports whether the label matches one of the ignore patterns.
+func (s *labelSetting) isIgnored(name string) bool {
+	for _, v := range s.Ignore {
+		if regexp.MustCompile("^" + v + "$").MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (l label) GetIssues(client *github.Client, user, repo, labelname string) ([]int, error) {
 
 	opt := &github.IssueListByRepoOptions{
@@ -305,8 +528,30 @@ func (l label) GetIssues(client *github.Client, user, repo, labelname string) ([
 	return issueNums, nil
 }
 
+// listLabels returns all labels of the repository.
+func listLabels(client *github.Client, owner, repo string) ([]*github.Label, error) {
+
+	opt := &github.ListOptions{Page: 1, PerPage: 100}
+
+	var labels []*github.Label
+	for {
+		list, resp, err := client.Issues.ListLabels(context.Background(), owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, list...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return labels, nil
+}
+
 type updOpe struct {
 	Name, Operation, Color, Desc string
+	CurrentColor                 string
 	Issues                       []int
 }
 
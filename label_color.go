@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// defaultPalette is the palette available in all label settings.
+// The colors are the same as the label color picker of GitHub.
+var defaultPalette = map[string]string{
+	"red":          "b60205",
+	"orange":       "d93f0b",
+	"yellow":       "fbca04",
+	"green":        "0e8a16",
+	"teal":         "006b75",
+	"blue":         "1d76db",
+	"navy":         "0052cc",
+	"purple":       "5319e7",
+	"light-red":    "e99695",
+	"light-orange": "f9d0c4",
+	"light-yellow": "fef2c0",
+	"light-green":  "c2e0c6",
+	"light-teal":   "bfdadc",
+	"light-blue":   "c5def5",
+	"light-navy":   "bfd4f2",
+	"light-purple": "d4c5f9",
+	"gray":         "ededed",
+	"black":        "000000",
+	"white":        "ffffff",
+}
+
+var paletteNamePtn = regexp.MustCompile("^[a-z][a-z0-9_-]*$")
+
+// isColorValue reports whether str is a hex color or a palette name.
+func isColorValue(str string) bool {
+	return labelColorPtn.MatchString(str) || paletteNamePtn.MatchString(str)
+}
+
+func paletteNames(palette map[string]string) []string {
+	names := make([]string, 0, len(palette))
+	for k := range palette {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// color returns the hex color of str, which is a hex color or a palette name.
+func (s *labelSetting) color(str string) (string, error) {
+	if labelColorPtn.MatchString(str) {
+		return str, nil
+	}
+	if c, ok := s.Palette[str]; ok {
+		return c, nil
+	}
+	if c, ok := defaultPalette[str]; ok {
+		return c, nil
+	}
+	return "", fmt.Errorf("undefined palette color (%s)", str)
+}
+
+// resolveColors replaces palette names with hex colors,
+// and colors the labels without color by the color schemes.
+func (s *labelSetting) resolveColors() error {
+
+	labelIdx := make(map[string]int)
+	for i, v := range s.Labels {
+		labelIdx[v.Name] = i
+	}
+
+	for _, v := range s.ColorSchemes {
+		names := v.Labels
+		if len(names) == 0 {
+			for _, g := range s.ExclusiveGroups {
+				if g.Name == v.Name {
+					names = g.Labels
+				}
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("labels of color scheme are not found (%s)", v.Name)
+		}
+		from, err := s.color(v.From)
+		if err != nil {
+			return err
+		}
+		to, err := s.color(v.To)
+		if err != nil {
+			return err
+		}
+		for i, name := range names {
+			idx, ok := labelIdx[name]
+			if !ok {
+				return fmt.Errorf("label name of `color_schemes` is not found in labels (%s)", name)
+			}
+			if s.Labels[idx].Color != "" {
+				continue
+			}
+			rate := 0.0
+			if len(names) > 1 {
+				rate = float64(i) / float64(len(names)-1)
+			}
+			s.Labels[idx].Color = gradientColor(from, to, rate)
+		}
+	}
+
+	for i, v := range s.Labels {
+		if v.Color == "" {
+			return fmt.Errorf("label color is not defined (%s)", v.Name)
+		}
+		c, err := s.color(v.Color)
+		if err != nil {
+			return err
+		}
+		s.Labels[i].Color = c
+	}
+
+	return nil
+}
+
+func parseColor(hex string) (r, g, b float64) {
+	v, _ := strconv.ParseUint(hex, 16, 32)
+	return float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff)
+}
+
+// gradientColor returns the color between from and to at the rate (0 to 1).
+func gradientColor(from, to string, rate float64) string {
+	r1, g1, b1 := parseColor(from)
+	r2, g2, b2 := parseColor(to)
+	mix := func(a, b float64) int {
+		return int(math.Round(a + (b-a)*rate))
+	}
+	return fmt.Sprintf("%02x%02x%02x", mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
+
+// textColor returns the text color which GitHub uses on the label color.
+func textColor(hex string) string {
+	r, g, b := parseColor(hex)
+	if (r*299+g*587+b*114)/1000 >= 128 {
+		return "000000"
+	}
+	return "ffffff"
+}
+
+// luminance returns the relative luminance defined in WCAG 2.0.
+func luminance(hex string) float64 {
+	r, g, b := parseColor(hex)
+	channel := func(c float64) float64 {
+		c /= 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b)
+}
+
+// contrastProblems returns labels whose contrast ratio is lower than min_contrast.
+// Colors must be resolved before calling this.
+func (s *labelSetting) contrastProblems() []string {
+	problems := []string{}
+	if s.MinContrast == nil {
+		return problems
+	}
+	for _, v := range s.Labels {
+		if ratio := contrastRatio(v.Color); ratio < *s.MinContrast {
+			problems = append(problems, fmt.Sprintf("`%s`: contrast ratio is too low (%.2f < %g)", v.Name, ratio, *s.MinContrast))
+		}
+	}
+	return problems
+}
+
+// contrastRatio returns the contrast ratio between the label color and its text color.
+func contrastRatio(hex string) float64 {
+	l1, l2 := luminance(hex), luminance(textColor(hex))
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
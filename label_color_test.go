@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+
+	cases := []struct {
+		color    string
+		text     string
+		expected float64
+	}{
+		{"000000", "ffffff", 21},
+		{"ffffff", "000000", 21},
+		{"ff3000", "ffffff", 3.70},
+		{"fbca04", "000000", 13.55},
+		{"5319e7", "ffffff", 7.89},
+		{"777777", "ffffff", 4.48},
+	}
+
+	for _, c := range cases {
+		if text := textColor(c.color); text != c.text {
+			t.Errorf("%s: text color is %s, expected %s", c.color, text, c.text)
+		}
+		if ratio := contrastRatio(c.color); math.Abs(ratio-c.expected) > 0.01 {
+			t.Errorf("%s: contrast ratio is %.2f, expected %.2f", c.color, ratio, c.expected)
+		}
+	}
+}
+
+func TestContrastProblems(t *testing.T) {
+
+	setting := &labelSetting{}
+	src := `{
+		"labels": [
+			{"name": "urgent", "color": "ff3000"},
+			{"name": "major", "color": "fbca04"},
+			{"name": "gray", "color": "777777"}
+		]
+	}`
+	if err := json.Unmarshal([]byte(src), setting); err != nil {
+		t.Fatal(err)
+	}
+
+	if problems := setting.contrastProblems(); len(problems) != 0 {
+		t.Errorf("problems without min_contrast are %v", problems)
+	}
+
+	setting.MinContrast = new(float64)
+	*setting.MinContrast = 4.5
+	expected := []string{
+		"`urgent`: contrast ratio is too low (3.70 < 4.5)",
+		"`gray`: contrast ratio is too low (4.48 < 4.5)",
+	}
+	if problems := setting.contrastProblems(); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("problems are %v, expected %v", problems, expected)
+	}
+}
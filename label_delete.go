@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// strategies for labels to delete which are attached to issues
+const (
+	deleteStrip     = "strip"
+	deleteKeep      = "keep"
+	deleteMigrateTo = "migrate-to:"
+)
+
+// parseDeleteStrategy returns the kind of the strategy, and the target label if it is "migrate-to:<label>".
+func parseDeleteStrategy(str string) (kind, target string, err error) {
+	switch {
+	case str == deleteStrip, str == deleteKeep:
+		return str, "", nil
+	case strings.HasPrefix(str, deleteMigrateTo) && len(str) > len(deleteMigrateTo):
+		return deleteMigrateTo, strings.TrimPrefix(str, deleteMigrateTo), nil
+	default:
+		return "", "", fmt.Errorf("invalid delete strategy, must be %s, %s<label> or %s (%s)", deleteStrip, deleteMigrateTo, deleteKeep, str)
+	}
+}
+
+// checkDeleteStrategy checks the strategy and its target label.
+func (s *labelSetting) checkDeleteStrategy(str string) error {
+	kind, target, err := parseDeleteStrategy(str)
+	if err != nil {
+		return err
+	}
+	if kind == deleteMigrateTo {
+		if item, ok := s.LabelMap[target]; !ok || item.ReplaceTo != "" || item.IsIgnore {
+			return fmt.Errorf("label name of `%s` is not found in labels (%s)", deleteMigrateTo, target)
+		}
+	}
+	return nil
+}
+
+// deleteStrategy returns the strategy for the label,
+// which is the first matched one in `on_delete`, or defaultStrategy if there is no matched one.
+func (s *labelSetting) deleteStrategy(name, defaultStrategy string) string {
+	for _, v := range s.OnDelete {
+		ptn := "^" + strings.Replace(v.Label, "*", ".*", -1) + "$"
+		if regexp.MustCompile(ptn).MatchString(name) {
+			return v.Strategy
+		}
+	}
+	return defaultStrategy
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// remoteSettingPrefix is the prefix of settings in other repositories
+// such as "github:owner/repo/path/to/labels.json@ref".
+const remoteSettingPrefix = "github:"
+
+// settingRef is a reference to a local setting file, or a setting file in another repository if Owner is set.
+type settingRef struct {
+	Owner, Repo, Ref, Path string
+}
+
+func (r settingRef) String() string {
+	if r.Owner == "" {
+		return r.Path
+	}
+	str := fmt.Sprintf("%s%s/%s/%s", remoteSettingPrefix, r.Owner, r.Repo, r.Path)
+	if r.Ref != "" {
+		str += "@" + r.Ref
+	}
+	return str
+}
+
+// resolve returns the reference of str, which is relative to r if it is a relative path.
+func (r settingRef) resolve(str string) (settingRef, error) {
+
+	if strings.HasPrefix(str, remoteSettingPrefix) {
+		ref := settingRef{}
+		str = strings.TrimPrefix(str, remoteSettingPrefix)
+		if idx := strings.LastIndex(str, "@"); idx >= 0 {
+			ref.Ref, str = str[idx+1:], str[:idx]
+		}
+		parts := strings.SplitN(str, "/", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return settingRef{}, fmt.Errorf("invalid remote setting, must be %sowner/repo/path[@ref] (%s)", remoteSettingPrefix, str)
+		}
+		ref.Owner, ref.Repo, ref.Path = parts[0], parts[1], parts[2]
+		return ref, nil
+	}
+
+	if r.Owner != "" {
+		ref := r
+		ref.Path = path.Join(path.Dir(r.Path), str)
+		return ref, nil
+	}
+
+	if !filepath.IsAbs(str) {
+		str = filepath.Join(filepath.Dir(r.Path), str)
+	}
+	return settingRef{Path: str}, nil
+}
+
+func (r settingRef) read(client *github.Client) ([]byte, error) {
+
+	if r.Owner == "" {
+		return ioutil.ReadFile(r.Path)
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("github client is required to read remote setting (%s)", r)
+	}
+	var opt *github.RepositoryContentGetOptions
+	if r.Ref != "" {
+		opt = &github.RepositoryContentGetOptions{Ref: r.Ref}
+	}
+	file, _, _, err := client.Repositories.GetContents(context.Background(), r.Owner, r.Repo, r.Path, opt)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, fmt.Errorf("remote setting is not a file (%s)", r)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// loadSettings decodes the setting file and merges the settings it extends recursively.
+func loadSettings(ref settingRef, client *github.Client, visiting map[string]bool) (*labelSetting, error) {
+
+	if visiting[ref.String()] {
+		return nil, fmt.Errorf("circular extend in setting file (%s)", ref)
+	}
+	visiting[ref.String()] = true
+	defer delete(visiting, ref.String())
+
+	src, err := ref.read(client)
+	if err != nil {
+		return nil, fmt.Errorf("not found config file (%s)", ref)
+	}
+
+	setting := &labelSetting{}
+	if err := decodeBytes(ref.Path, src, setting); err != nil {
+		return nil, fmt.Errorf("something wrong in setting file (%s): %s", ref, err)
+	}
+	if problems := setting.validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("something wrong in setting file (%s): %s", ref, problems[0])
+	}
+
+	if len(setting.Extend) == 0 {
+		return setting, nil
+	}
+
+	bases := []*labelSetting{}
+	names := []string{}
+	for _, v := range setting.Extend {
+		baseRef, err := ref.resolve(v)
+		if err != nil {
+			return nil, err
+		}
+		base, err := loadSettings(baseRef, client, visiting)
+		if err != nil {
+			return nil, err
+		}
+		bases = append(bases, base)
+		names = append(names, baseRef.String())
+	}
+
+	return mergeSettings(setting, bases, names)
+}
+
+// mergeSettings merges the extended settings and the settings of the child.
+// The child overrides the extended settings, but the extended settings must not conflict with each other.
+func mergeSettings(child *labelSetting, bases []*labelSetting, names []string) (*labelSetting, error) {
+
+	merged := &labelSetting{Palette: make(map[string]string)}
+	labelIdx := make(map[string]int)
+	replaceIdx := make(map[string]int)
+	groupIdx := make(map[string]int)
+	schemeIdx := make(map[string]int)
+	sources := make(map[string]string)
+
+	conflict := func(kind, name, src string) error {
+		return fmt.Errorf("%s conflicts between extended settings (%s: %s, %s)", kind, name, sources[kind+name], src)
+	}
+
+	for i, base := range bases {
+		for _, v := range base.Labels {
+			if idx, ok := labelIdx[v.Name]; ok {
+				if merged.Labels[idx] != v {
+					return nil, conflict("label", v.Name, names[i])
+				}
+				continue
+			}
+			labelIdx[v.Name] = len(merged.Labels)
+			sources["label"+v.Name] = names[i]
+			merged.Labels = append(merged.Labels, v)
+		}
+		for _, v := range base.Replace {
+			if idx, ok := replaceIdx[v.From]; ok {
+				if merged.Replace[idx] != v {
+					return nil, conflict("replace", v.From, names[i])
+				}
+				continue
+			}
+			replaceIdx[v.From] = len(merged.Replace)
+			sources["replace"+v.From] = names[i]
+			merged.Replace = append(merged.Replace, v)
+		}
+		for _, v := range base.ExclusiveGroups {
+			if idx, ok := groupIdx[v.Name]; ok {
+				if !reflect.DeepEqual(merged.ExclusiveGroups[idx], v) {
+					return nil, conflict("exclusive group", v.Name, names[i])
+				}
+				continue
+			}
+			groupIdx[v.Name] = len(merged.ExclusiveGroups)
+			sources["exclusive group"+v.Name] = names[i]
+			merged.ExclusiveGroups = append(merged.ExclusiveGroups, v)
+		}
+		for _, v := range base.ColorSchemes {
+			if idx, ok := schemeIdx[v.Name]; ok {
+				if !reflect.DeepEqual(merged.ColorSchemes[idx], v) {
+					return nil, conflict("color scheme", v.Name, names[i])
+				}
+				continue
+			}
+			schemeIdx[v.Name] = len(merged.ColorSchemes)
+			sources["color scheme"+v.Name] = names[i]
+			merged.ColorSchemes = append(merged.ColorSchemes, v)
+		}
+		for _, k := range paletteNames(base.Palette) {
+			if c, ok := merged.Palette[k]; ok {
+				if c != base.Palette[k] {
+					return nil, conflict("palette", k, names[i])
+				}
+				continue
+			}
+			sources["palette"+k] = names[i]
+			merged.Palette[k] = base.Palette[k]
+		}
+		for _, v := range base.Ignore {
+			if !existStr(merged.Ignore, v) {
+				merged.Ignore = append(merged.Ignore, v)
+			}
+		}
+		if merged.MinContrast == nil {
+			merged.MinContrast = base.MinContrast
+		}
+	}
+
+	// the child overrides the extended settings
+	childLabels := []string{}
+	for _, v := range child.Labels {
+		childLabels = append(childLabels, v.Name)
+		if idx, ok := labelIdx[v.Name]; ok {
+			merged.Labels[idx] = v
+		} else {
+			merged.Labels = append(merged.Labels, v)
+		}
+	}
+	childReplaces := []string{}
+	for _, v := range child.Replace {
+		childReplaces = append(childReplaces, v.From)
+		if idx, ok := replaceIdx[v.From]; ok {
+			merged.Replace[idx] = v
+		} else {
+			merged.Replace = append(merged.Replace, v)
+		}
+	}
+	for _, v := range child.ExclusiveGroups {
+		if idx, ok := groupIdx[v.Name]; ok {
+			merged.ExclusiveGroups[idx] = v
+		} else {
+			merged.ExclusiveGroups = append(merged.ExclusiveGroups, v)
+		}
+	}
+	for _, v := range child.ColorSchemes {
+		if idx, ok := schemeIdx[v.Name]; ok {
+			merged.ColorSchemes[idx] = v
+		} else {
+			merged.ColorSchemes = append(merged.ColorSchemes, v)
+		}
+	}
+	for k, v := range child.Palette {
+		merged.Palette[k] = v
+	}
+	for _, v := range child.Ignore {
+		if !existStr(merged.Ignore, v) {
+			merged.Ignore = append(merged.Ignore, v)
+		}
+	}
+	if child.MinContrast != nil {
+		merged.MinContrast = child.MinContrast
+	}
+
+	// on_delete is matched in order, so the child comes first
+	merged.OnDelete = child.OnDelete
+	for _, base := range bases {
+		merged.OnDelete = append(merged.OnDelete, base.OnDelete...)
+	}
+
+	// a label defined in the child replaces the extended replace setting, and vice versa
+	labels := merged.Labels[:0]
+	for _, v := range merged.Labels {
+		if existStr(childLabels, v.Name) || !existStr(childReplaces, v.Name) {
+			labels = append(labels, v)
+		}
+	}
+	merged.Labels = labels
+	replaces := merged.Replace[:0]
+	for _, v := range merged.Replace {
+		if existStr(childReplaces, v.From) || !existStr(childLabels, v.From) {
+			replaces = append(replaces, v)
+		}
+	}
+	merged.Replace = replaces
+
+	return merged, nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// labelReview lets the user select and edit the label operations interactively.
+type labelReview struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+const labelReviewHelp = "  commands: <num> toggle, a select all, n select none, c <num> <color> edit color, t <num> <label> edit target label, y apply, q quit"
+
+// Review returns the selected operations.
+// ok is false if the user quits without applying.
+func (r labelReview) Review(updOpes []updOpe, setting *labelSetting) (selected []updOpe, ok bool, err error) {
+
+	opes := make([]updOpe, len(updOpes))
+	copy(opes, updOpes)
+	checked := make([]bool, len(opes))
+	for i := range checked {
+		checked[i] = true
+	}
+	pairs := r.pairs(opes)
+
+	scanner := bufio.NewScanner(r.In)
+	for {
+		fmt.Fprintln(r.Out, "  * review operations")
+		for i, v := range opes {
+			mark := " "
+			if checked[i] {
+				mark = "x"
+			}
+			desc := r.describe(v)
+			if p := pairs[i]; p >= 0 && v.Operation == opeDel {
+				desc = fmt.Sprintf("`%s`: delete after adding `%s` to issues (with %d)", v.Name, opes[p].Name, p+1)
+			}
+			fmt.Fprintf(r.Out, "    [%s] %d: %s\n", mark, i+1, desc)
+		}
+		fmt.Fprintln(r.Out, labelReviewHelp)
+		fmt.Fprint(r.Out, "  > ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(r.Out, "")
+			return nil, false, scanner.Err()
+		}
+		args := r.parseCommand(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		switch args[0] {
+		case "y":
+			if err := r.check(opes, checked); err != nil {
+				fmt.Fprintf(r.Out, "  %s\n", err)
+				continue
+			}
+			for i, v := range opes {
+				if checked[i] {
+					selected = append(selected, v)
+				}
+			}
+			return selected, true, nil
+		case "q":
+			fmt.Fprintln(r.Out, "  quit without update")
+			return nil, false, nil
+		case "a", "n":
+			for i := range checked {
+				checked[i] = args[0] == "a"
+			}
+		case "c", "t":
+			if len(args) != 3 {
+				fmt.Fprintf(r.Out, "  usage: %s <num> <value>\n", args[0])
+				continue
+			}
+			idx, err := r.index(args[1], len(opes))
+			if err != nil {
+				fmt.Fprintf(r.Out, "  %s\n", err)
+				continue
+			}
+			if err := r.edit(&opes[idx], args[0], args[2], setting); err != nil {
+				fmt.Fprintf(r.Out, "  %s\n", err)
+				continue
+			}
+			checked[idx] = true
+			if p := pairs[idx]; p >= 0 {
+				checked[p] = true
+			}
+		default:
+			idx, err := r.index(args[0], len(opes))
+			if err != nil {
+				fmt.Fprintf(r.Out, "  %s\n", err)
+				continue
+			}
+			checked[idx] = !checked[idx]
+			if p := pairs[idx]; p >= 0 {
+				checked[p] = checked[idx]
+			}
+		}
+	}
+}
+
+// pairs returns the index of the paired operation for each operation, or -1.
+// plan adds the issues of a replaced or migrated label to the target just before deleting it,
+// so these two operations are selected together.
+func (r labelReview) pairs(opes []updOpe) []int {
+	pairs := make([]int, len(opes))
+	for i := range pairs {
+		pairs[i] = -1
+	}
+	for i := 0; i+1 < len(opes); i++ {
+		if opes[i].Operation == opeIss && opes[i+1].Operation == opeDel {
+			pairs[i], pairs[i+1] = i+1, i
+		}
+	}
+	return pairs
+}
+
+// check returns an error if the selected operations can't be applied,
+// e.g. the label is added to issues but the creation of the label is not selected.
+func (r labelReview) check(opes []updOpe, checked []bool) error {
+	for i, v := range opes {
+		if !checked[i] || v.Operation != opeIss {
+			continue
+		}
+		for j, v2 := range opes {
+			if v2.Operation == opeCrt && v2.Name == v.Name && !checked[j] {
+				return fmt.Errorf("`%s` is added to issues in %d, so select the creation in %d", v.Name, i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCommand splits the line into the command and at most two arguments.
+// The last argument is the rest of the line, so it can be a label name with spaces.
+func (r labelReview) parseCommand(line string) []string {
+	args := []string{}
+	line = strings.TrimSpace(line)
+	for line != "" {
+		if len(args) == 2 {
+			args = append(args, line)
+			break
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			args = append(args, line)
+			break
+		}
+		args = append(args, line[:i])
+		line = strings.TrimSpace(line[i+1:])
+	}
+	return args
+}
+
+func (r labelReview) describe(v updOpe) string {
+	switch v.Operation {
+	case opeCrt:
+		return fmt.Sprintf("`%s`: create (color=\"%s\")", v.Name, v.Color)
+	case opeUpd:
+		return fmt.Sprintf("`%s`: update (color=\"%s\" -> \"%s\")", v.Name, v.CurrentColor, v.Color)
+	case opeIss:
+		return fmt.Sprintf("`%s`: add to issues (issues=%s)", v.Name, concatInt(v.Issues, ", "))
+	case opeDel:
+		if len(v.Issues) > 0 {
+			return fmt.Sprintf("`%s`: strip from issues (issues=%s) and delete", v.Name, concatInt(v.Issues, ", "))
+		}
+		return fmt.Sprintf("`%s`: delete", v.Name)
+	default:
+		return fmt.Sprintf("`%s`: %s", v.Name, v.Operation)
+	}
+}
+
+func (r labelReview) index(str string, length int) (int, error) {
+	num, err := strconv.Atoi(str)
+	if err != nil || num < 1 || num > length {
+		return 0, fmt.Errorf("invalid operation number (%s)", str)
+	}
+	return num - 1, nil
+}
+
+func (r labelReview) edit(ope *updOpe, command, value string, setting *labelSetting) error {
+	switch command {
+	case "c":
+		if ope.Operation != opeCrt && ope.Operation != opeUpd {
+			return fmt.Errorf("color can be edited for create or update only (%s)", ope.Operation)
+		}
+		color, err := setting.color(value)
+		if err != nil {
+			return err
+		}
+		ope.Color = color
+	case "t":
+		if ope.Operation != opeIss {
+			return fmt.Errorf("target label can be edited for add to issues only (%s)", ope.Operation)
+		}
+		if item, ok := setting.LabelMap[value]; !ok || item.ReplaceTo != "" || item.IsIgnore {
+			return fmt.Errorf("target label is not found in labels (%s)", value)
+		}
+		ope.Name = value
+	}
+	return nil
+}
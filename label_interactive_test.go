@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLabelReviewParseCommand(t *testing.T) {
+
+	cases := []struct {
+		line     string
+		expected []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"y", []string{"y"}},
+		{" 3 ", []string{"3"}},
+		{"c 2 red", []string{"c", "2", "red"}},
+		{"c  2   ff3000 ", []string{"c", "2", "ff3000"}},
+		{"t 3 help wanted", []string{"t", "3", "help wanted"}},
+		{"t\t3\tgood first issue", []string{"t", "3", "good first issue"}},
+		{"t 3", []string{"t", "3"}},
+	}
+
+	for _, c := range cases {
+		if args := (labelReview{}).parseCommand(c.line); !reflect.DeepEqual(args, c.expected) {
+			t.Errorf("%q: args are %q, expected %q", c.line, args, c.expected)
+		}
+	}
+}
+
+// testReviewOpes are operations in the order of plan,
+// and 3 and 4 are the pair of a replaced label.
+func testReviewOpes() []updOpe {
+	return []updOpe{
+		{Name: "help wanted", Operation: opeCrt, Color: "008672"},
+		{Name: "bug", Operation: opeUpd, Color: "e03000", CurrentColor: "d73a4a"},
+		{Name: "help wanted", Operation: opeIss, Issues: []int{10, 13}},
+		{Name: "wontfix", Operation: opeDel},
+		{Name: "question", Operation: opeDel},
+	}
+}
+
+func testReviewSetting() *labelSetting {
+	return &labelSetting{
+		LabelMap: map[string]labelItem{
+			"help wanted":      {Color: "008672"},
+			"good first issue": {Color: "7057ff"},
+			"bug":              {Color: "e03000"},
+			"wontfix":          {ReplaceTo: "help wanted"},
+			"area/.*":          {IsIgnore: true},
+		},
+	}
+}
+
+func TestLabelReview(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		input    string
+		ok       bool
+		selected []string
+		output   string
+	}{
+		{
+			name:     "apply all",
+			input:    "y\n",
+			ok:       true,
+			selected: []string{"help wanted create", "bug update", "help wanted add issues", "wontfix delete", "question delete"},
+		},
+		{
+			name:  "quit",
+			input: "q\n",
+			ok:    false,
+		},
+		{
+			name:  "end of input",
+			input: "2\n",
+			ok:    false,
+		},
+		{
+			name:     "toggle",
+			input:    "2\n5\n5\n y \n",
+			ok:       true,
+			selected: []string{"help wanted create", "help wanted add issues", "wontfix delete", "question delete"},
+		},
+		{
+			// the pair is toggled together from either operation
+			name:     "toggle pair",
+			input:    "4\ny\n",
+			ok:       true,
+			selected: []string{"help wanted create", "bug update", "question delete"},
+		},
+		{
+			name:   "add issues without creation",
+			input:  "n\n3\ny\n",
+			ok:     false,
+			output: "`help wanted` is added to issues in 3, so select the creation in 1",
+		},
+		{
+			name:     "select none and pair",
+			input:    "n\n1\n3\ny\n",
+			ok:       true,
+			selected: []string{"help wanted create", "help wanted add issues", "wontfix delete"},
+		},
+		{
+			name:     "edit target with space",
+			input:    "t 3 good first issue\ny\n",
+			ok:       true,
+			selected: []string{"help wanted create", "bug update", "good first issue add issues", "wontfix delete", "question delete"},
+			output:   "`wontfix`: delete after adding `good first issue` to issues (with 3)",
+		},
+		{
+			// editing the operation selects its pair
+			name:     "edit target of deselected pair",
+			input:    "4\nt 3 bug\ny\n",
+			ok:       true,
+			selected: []string{"help wanted create", "bug update", "bug add issues", "wontfix delete", "question delete"},
+		},
+		{
+			name:     "edit color",
+			input:    "c 1 ffffff\ny\n",
+			ok:       true,
+			selected: []string{"help wanted create", "bug update", "help wanted add issues", "wontfix delete", "question delete"},
+		},
+		{
+			name:   "invalid commands",
+			input:  "9\nx\nc 4 ffffff\nt 3 wontfix\nt 3 area/web\nt 3\nq\n",
+			ok:     false,
+			output: "invalid operation number (9)",
+		},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		selected, ok, err := labelReview{In: strings.NewReader(c.input), Out: out}.Review(testReviewOpes(), testReviewSetting())
+		if err != nil {
+			t.Errorf("%s: Review returns error: %s", c.name, err)
+			continue
+		}
+		if ok != c.ok {
+			t.Errorf("%s: ok is %t, expected %t", c.name, ok, c.ok)
+		}
+		if c.ok {
+			list := []string{}
+			for _, v := range selected {
+				list = append(list, v.Name+" "+v.Operation)
+			}
+			if !reflect.DeepEqual(list, c.selected) {
+				t.Errorf("%s: selected operations are %v, expected %v", c.name, list, c.selected)
+			}
+		}
+		if !strings.Contains(out.String(), c.output) {
+			t.Errorf("%s: output doesn't contain %q\n%s", c.name, c.output, out.String())
+		}
+	}
+}
+
+func TestLabelReviewCheck(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		checked []bool
+		valid   bool
+	}{
+		{"all", []bool{true, true, true, true, true}, true},
+		{"none", []bool{false, false, false, false, false}, true},
+		{"without creation", []bool{false, true, true, true, true}, false},
+		{"creation only", []bool{true, false, false, false, false}, true},
+		{"delete only", []bool{false, false, false, false, true}, true},
+	}
+
+	for _, c := range cases {
+		err := labelReview{}.check(testReviewOpes(), c.checked)
+		if c.valid && err != nil {
+			t.Errorf("%s: check returns error: %s", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: check doesn't return error", c.name)
+		}
+	}
+
+	if pairs := (labelReview{}).pairs(testReviewOpes()); !reflect.DeepEqual(pairs, []int{-1, -1, 3, 2, -1}) {
+		t.Errorf("pairs are %v", pairs)
+	}
+}
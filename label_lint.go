@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func labelLintCmd() cli.Command {
+	return cli.Command{
+		Name:  "lint",
+		Usage: "list issues or pull requests violating exclusive label groups, and labels with low contrast",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelLint{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "label_settings.json",
+				Usage: "you can change label setting json file",
+			},
+			cli.BoolFlag{
+				Name:  "repo-settings, r",
+				Usage: "read label settings from " + repoSettingPath + " in the default branch of the repository",
+			},
+			cli.BoolFlag{
+				Name:  "fix",
+				Usage: "keep the highest-ranked label in each group and remove the others",
+			},
+		},
+	}
+}
+
+type labelLint struct {
+	Out io.Writer
+}
+
+func (l labelLint) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	setting, err := label{}.ReadSettings(labelSettingFile(c, conf), client)
+	if err != nil {
+		return err
+	}
+
+	issues, err := issue{}.getAllIssues(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	violations := l.findViolations(issues, setting, conf.getLabels(""))
+
+	// output
+	fmt.Fprintf(l.Out, "# Label lint for `%s/%s`\n", *conf.User, *conf.Repo)
+	if problems := setting.contrastProblems(); len(problems) > 0 {
+		for _, v := range problems {
+			fmt.Fprintf(l.Out, "    %s\n", v)
+		}
+		fmt.Fprintln(l.Out, "")
+	}
+	if len(violations) == 0 {
+		fmt.Fprintln(l.Out, "    there are no violations")
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Fprintf(l.Out, "    %d: `%s` has %s (keep `%s`)\n", v.Number, v.Group, concatStrWithBracket(v.Labels, ", ", "`"), v.Labels[0])
+	}
+	fmt.Fprintln(l.Out, "")
+
+	if !c.Bool("fix") {
+		return nil
+	}
+
+	fmt.Fprintln(l.Out, "  Update in progress...")
+	for _, v := range violations {
+		for _, name := range v.Labels[1:] {
+			_, err = client.Issues.RemoveLabelForIssue(context.Background(), *conf.User, *conf.Repo, v.Number, name)
+			if err != nil {
+				fmt.Fprintf(l.Out, "    `%s` -> remove fail (issue num = %d) (err=\"%s\")\n", name, v.Number, err.Error())
+			} else {
+				fmt.Fprintf(l.Out, "    `%s` -> remove success (issue num = %d)\n", name, v.Number)
+			}
+		}
+	}
+
+	return nil
+}
+
+// findViolations returns issues which have several labels of the same exclusive group.
+// The labels of each violation are sorted by rank, the order in label_rule first and then the order in the group.
+func (l labelLint) findViolations(issues []*github.Issue, setting *labelSetting, rankedLabels []string) []labelViolation {
+
+	violations := []labelViolation{}
+
+	for _, is := range issues {
+		for _, g := range setting.ExclusiveGroups {
+			labels := []string{}
+			for _, label := range is.Labels {
+				if existStr(g.Labels, *label.Name) {
+					labels = append(labels, *label.Name)
+				}
+			}
+			if len(labels) < 2 {
+				continue
+			}
+			rank := func(name string) int {
+				for i, v := range rankedLabels {
+					if v == name {
+						return i
+					}
+				}
+				for i, v := range g.Labels {
+					if v == name {
+						return len(rankedLabels) + i
+					}
+				}
+				return len(rankedLabels) + len(g.Labels)
+			}
+			sort.SliceStable(labels, func(i, j int) bool {
+				return rank(labels[i]) < rank(labels[j])
+			})
+			violations = append(violations, labelViolation{Number: *is.Number, Group: g.Name, Labels: labels})
+		}
+	}
+
+	return violations
+}
+
+type labelViolation struct {
+	Number int
+	Group  string
+	Labels []string
+}
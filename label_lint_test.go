@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestLabelLintFindViolations(t *testing.T) {
+
+	setting := &labelSetting{}
+	src := `{"exclusive_groups": [
+		{"name": "priority", "labels": ["urgent", "major", "minor", "pending"]},
+		{"name": "size", "labels": ["size/S", "size/L"]}
+	]}`
+	if err := json.Unmarshal([]byte(src), setting); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []*github.Issue{
+		newTestIssue(1, []string{"urgent", "bug", "size/L"}, nil),
+		newTestIssue(2, []string{"pending", "urgent"}, nil),
+		newTestIssue(3, []string{"minor", "size/S", "major", "size/L"}, nil),
+		newTestIssue(4, nil, nil),
+	}
+
+	cases := []struct {
+		name         string
+		rankedLabels []string
+		expected     []labelViolation
+	}{
+		{
+			name: "order in group",
+			expected: []labelViolation{
+				{Number: 2, Group: "priority", Labels: []string{"urgent", "pending"}},
+				{Number: 3, Group: "priority", Labels: []string{"major", "minor"}},
+				{Number: 3, Group: "size", Labels: []string{"size/S", "size/L"}},
+			},
+		},
+		{
+			// the order in label_rule comes first
+			name:         "order in label_rule",
+			rankedLabels: []string{"pending", "minor"},
+			expected: []labelViolation{
+				{Number: 2, Group: "priority", Labels: []string{"pending", "urgent"}},
+				{Number: 3, Group: "priority", Labels: []string{"minor", "major"}},
+				{Number: 3, Group: "size", Labels: []string{"size/S", "size/L"}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if violations := (labelLint{}).findViolations(issues, setting, c.rankedLabels); !reflect.DeepEqual(violations, c.expected) {
+			t.Errorf("%s: violations are %v, expected %v", c.name, violations, c.expected)
+		}
+	}
+}
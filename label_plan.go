@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func labelPlanCmd() cli.Command {
+	return cli.Command{
+		Name:  "plan",
+		Usage: "write the label operations to a plan file to review it before applying",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelPlanner{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "label_settings.json",
+				Usage: "you can change label setting json file",
+			},
+			cli.BoolFlag{
+				Name:  "repo-settings, r",
+				Usage: "read label settings from " + repoSettingPath + " in the default branch of the repository",
+			},
+			cli.StringFlag{
+				Name:  "strategy, s",
+				Usage: "strategy for labels to delete which are attached to issues (strip, migrate-to:<label> or keep)",
+			},
+			cli.StringFlag{
+				Name:  "out, o",
+				Value: "plan.json",
+				Usage: "output plan file",
+			},
+		},
+	}
+}
+
+func labelApplyCmd() cli.Command {
+	return cli.Command{
+		Name:      "apply",
+		Usage:     "apply the plan file after checking it against the current repository",
+		ArgsUsage: "<plan file>",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelApplier{Out: c.App.Writer})
+		},
+	}
+}
+
+// labelPlan is the plan file format.
+type labelPlan struct {
+	Repository string         `json:"repository"`
+	Operations []labelPlanOpe `json:"operations"`
+}
+
+// labelPlanOpe is an operation in the plan file.
+// Before and After are the label state before and after the operation, and nil if the label doesn't exist.
+// Before has the label id to find the label deleted and created again with the same name.
+type labelPlanOpe struct {
+	Name      string      `json:"name"`
+	Operation string      `json:"operation"`
+	Before    *labelState `json:"before"`
+	After     *labelState `json:"after"`
+	Issues    []int       `json:"issues,omitempty"`
+}
+
+type labelState struct {
+	ID    int64  `json:"id,omitempty"`
+	Color string `json:"color"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+// labelDescMediaType is the media type to get label descriptions from older github enterprise.
+const labelDescMediaType = "application/vnd.github.symmetra-preview+json"
+
+// listLabelStates returns the state of the repository labels by the label name.
+// It doesn't use Issues.ListLabels because github.Label doesn't have Description in v15.0.0.
+func listLabelStates(client *github.Client, owner, repo string) (map[string]labelState, error) {
+
+	states := make(map[string]labelState)
+	for page := 1; page != 0; {
+		req, err := client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/labels?per_page=100&page=%d", owner, repo, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", labelDescMediaType)
+
+		var list []struct {
+			ID          int64  `json:"id"`
+			Name        string `json:"name"`
+			Color       string `json:"color"`
+			Description string `json:"description"`
+		}
+		resp, err := client.Do(context.Background(), req, &list)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range list {
+			states[v.Name] = labelState{ID: v.ID, Color: v.Color, Desc: v.Description}
+		}
+		page = resp.NextPage
+	}
+
+	return states, nil
+}
+
+type labelPlanner struct {
+	Out io.Writer
+}
+
+func (l labelPlanner) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	setting, err := label{}.ReadSettings(labelSettingFile(c, conf), client)
+	if err != nil {
+		return err
+	}
+
+	updOpes, ok, err := label{Out: l.Out}.plan(conf, client, setting, c.String("strategy"))
+	if err != nil || !ok {
+		return err
+	}
+
+	states, err := listLabelStates(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+	before := func(v updOpe) *labelState {
+		if state, ok := states[v.Name]; ok {
+			return &state
+		}
+		return &labelState{Color: v.CurrentColor}
+	}
+
+	plan := labelPlan{
+		Repository: fmt.Sprintf("%s/%s", *conf.User, *conf.Repo),
+		Operations: []labelPlanOpe{},
+	}
+	for _, v := range updOpes {
+		ope := labelPlanOpe{Name: v.Name, Operation: v.Operation, Issues: v.Issues}
+		switch v.Operation {
+		case opeCrt:
+			ope.After = &labelState{Color: v.Color, Desc: v.Desc}
+		case opeUpd:
+			ope.Before = before(v)
+			ope.After = &labelState{Color: v.Color, Desc: v.Desc}
+		case opeDel:
+			ope.Before = before(v)
+		}
+		plan.Operations = append(plan.Operations, ope)
+	}
+
+	jsonStr, err := json.MarshalIndent(plan, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.String("out"), append(jsonStr, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write plan file (%s)", c.String("out"))
+	}
+	fmt.Fprintf(l.Out, "  Plan is saved to %s (operations=%d)\n", c.String("out"), len(plan.Operations))
+
+	return nil
+}
+
+type labelApplier struct {
+	Out io.Writer
+}
+
+func (l labelApplier) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	filename := c.Args().First()
+	if filename == "" {
+		return fmt.Errorf("plan file is required")
+	}
+
+	plan := labelPlan{}
+	if err := decodeFile(filename, &plan); err != nil {
+		return fmt.Errorf("something wrong in plan file (%s): %s", filename, err)
+	}
+
+	repository := fmt.Sprintf("%s/%s", *conf.User, *conf.Repo)
+	if plan.Repository != repository {
+		return fmt.Errorf("plan is for another repository (%s)", plan.Repository)
+	}
+
+	fmt.Fprintf(l.Out, "# Label apply for `%s`\n", repository)
+
+	problems, err := l.check(plan, conf, client)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		fmt.Fprintln(l.Out, "  * plan is out of date")
+		for _, v := range problems {
+			fmt.Fprintf(l.Out, "    - %s\n", v)
+		}
+		return fmt.Errorf("plan doesn't match the current repository (problems=%d)", len(problems))
+	}
+
+	updOpes := []updOpe{}
+	for _, v := range plan.Operations {
+		ope := updOpe{Name: v.Name, Operation: v.Operation, Issues: v.Issues}
+		if v.After != nil {
+			ope.Color, ope.Desc = v.After.Color, v.After.Desc
+		}
+		updOpes = append(updOpes, ope)
+	}
+	label{Out: l.Out}.execute(conf, client, updOpes)
+
+	return nil
+}
+
+// check returns the operations which can't be applied to the current repository as planned.
+func (l labelApplier) check(plan labelPlan, conf *config, client *github.Client) ([]string, error) {
+
+	current, err := listLabelStates(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	addProblem := func(ope labelPlanOpe, format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf("`%s` %s: ", ope.Name, ope.Operation)+fmt.Sprintf(format, a...))
+	}
+	// compare adds problems if the current label is not the one before the operation.
+	// The id is compared only if both have it, because labels created in the plan don't have it.
+	compare := func(ope labelPlanOpe, state labelState) {
+		if ope.Before.ID != 0 && state.ID != 0 && ope.Before.ID != state.ID {
+			addProblem(ope, "label is deleted and created again (id=%d -> %d)", ope.Before.ID, state.ID)
+		}
+		if ope.Before.Color != state.Color {
+			addProblem(ope, "color is changed (\"%s\" -> \"%s\")", ope.Before.Color, state.Color)
+		}
+		if ope.Before.Desc != state.Desc {
+			addProblem(ope, "desc is changed (\"%s\" -> \"%s\")", ope.Before.Desc, state.Desc)
+		}
+	}
+
+	var covered []int
+	for _, v := range plan.Operations {
+		state, exists := current[v.Name]
+		switch v.Operation {
+		case opeCrt:
+			if exists {
+				addProblem(v, "label already exists")
+			}
+			if v.After == nil || !labelColorPtn.MatchString(v.After.Color) {
+				addProblem(v, "invalid state after operation")
+				continue
+			}
+			current[v.Name] = labelState{Color: v.After.Color, Desc: v.After.Desc}
+		case opeUpd:
+			if v.Before == nil || v.After == nil || !labelColorPtn.MatchString(v.After.Color) {
+				addProblem(v, "invalid state before or after operation")
+				continue
+			}
+			if !exists {
+				addProblem(v, "label doesn't exist")
+			} else {
+				compare(v, state)
+			}
+			current[v.Name] = labelState{ID: state.ID, Color: v.After.Color, Desc: v.After.Desc}
+		case opeDel:
+			if v.Before == nil {
+				addProblem(v, "invalid state before operation")
+				continue
+			}
+			if !exists {
+				addProblem(v, "label doesn't exist")
+			} else {
+				compare(v, state)
+			}
+			issueNums, err := label{}.GetIssues(client, *conf.User, *conf.Repo, v.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, num := range issueNums {
+				if !existInt(covered, num) && !existInt(v.Issues, num) {
+					addProblem(v, "label is attached to issues not in plan (issue num = %d)", num)
+				}
+			}
+			delete(current, v.Name)
+		case opeIss:
+			if !exists {
+				addProblem(v, "label doesn't exist")
+			}
+		default:
+			addProblem(v, "undefined operation")
+		}
+
+		covered = nil
+		if v.Operation == opeIss {
+			covered = v.Issues
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// newTestLabelClient returns the client of a github api server which has the labels,
+// and `wontfix` is attached to issue 3.
+func newTestLabelClient(t *testing.T) (*github.Client, func()) {
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/repos/o/r/labels":
+			if r.Header.Get("Accept") != labelDescMediaType {
+				t.Errorf("accept header is %q", r.Header.Get("Accept"))
+			}
+			w.Write([]byte(`[
+				{"id": 1, "name": "bug", "color": "d73a4a", "description": "Something isn't working"},
+				{"id": 2, "name": "wontfix", "color": "ffffff"},
+				{"id": 5, "name": "question", "color": "d876e3"}
+			]`))
+		case r.URL.Path == "/repos/o/r/issues" && r.URL.Query().Get("labels") == "wontfix":
+			w.Write([]byte(`[{"number": 3}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(api.URL + "/")
+	return client, api.Close
+}
+
+func TestLabelApplierCheck(t *testing.T) {
+
+	client, closeAPI := newTestLabelClient(t)
+	defer closeAPI()
+
+	user, repo := "o", "r"
+	conf := &config{User: &user, Repo: &repo}
+
+	bugUpd := labelPlanOpe{
+		Name: "bug", Operation: opeUpd,
+		Before: &labelState{ID: 1, Color: "d73a4a", Desc: "Something isn't working"},
+		After:  &labelState{Color: "e03000", Desc: "Something isn't working"},
+	}
+	update := func(ope labelPlanOpe, f func(before *labelState)) labelPlanOpe {
+		before := *ope.Before
+		f(&before)
+		ope.Before = &before
+		return ope
+	}
+
+	cases := []struct {
+		name     string
+		opes     []labelPlanOpe
+		problems []string
+	}{
+		{
+			name: "up to date",
+			opes: []labelPlanOpe{
+				{Name: "help wanted", Operation: opeCrt, After: &labelState{Color: "008672"}},
+				bugUpd,
+				{Name: "help wanted", Operation: opeIss, Issues: []int{3}},
+				{Name: "wontfix", Operation: opeDel, Before: &labelState{ID: 2, Color: "ffffff"}, Issues: []int{3}},
+				{Name: "question", Operation: opeDel, Before: &labelState{ID: 5, Color: "d876e3"}},
+			},
+			problems: []string{},
+		},
+		{
+			name:     "plan without id",
+			opes:     []labelPlanOpe{update(bugUpd, func(b *labelState) { b.ID = 0 })},
+			problems: []string{},
+		},
+		{
+			name:     "color is changed",
+			opes:     []labelPlanOpe{update(bugUpd, func(b *labelState) { b.Color = "ee0701" })},
+			problems: []string{"`bug` update: color is changed (\"ee0701\" -> \"d73a4a\")"},
+		},
+		{
+			name:     "desc is changed",
+			opes:     []labelPlanOpe{update(bugUpd, func(b *labelState) { b.Desc = "" })},
+			problems: []string{"`bug` update: desc is changed (\"\" -> \"Something isn't working\")"},
+		},
+		{
+			name: "deleted and created again",
+			opes: []labelPlanOpe{
+				update(bugUpd, func(b *labelState) { b.ID = 4 }),
+				{Name: "question", Operation: opeDel, Before: &labelState{ID: 3, Color: "d876e3"}},
+			},
+			problems: []string{
+				"`bug` update: label is deleted and created again (id=4 -> 1)",
+				"`question` delete: label is deleted and created again (id=3 -> 5)",
+			},
+		},
+		{
+			name: "label state",
+			opes: []labelPlanOpe{
+				{Name: "bug", Operation: opeCrt, After: &labelState{Color: "d73a4a"}},
+				{Name: "help wanted", Operation: opeUpd, Before: &labelState{Color: "008672"}, After: &labelState{Color: "008672"}},
+				{Name: "help wanted", Operation: opeIss, Issues: []int{3}},
+				{Name: "duplicate", Operation: opeDel, Before: &labelState{Color: "cfd3d7"}},
+			},
+			problems: []string{
+				"`bug` create: label already exists",
+				"`help wanted` update: label doesn't exist",
+				"`duplicate` delete: label doesn't exist",
+			},
+		},
+		{
+			name: "issues not in plan",
+			opes: []labelPlanOpe{
+				{Name: "wontfix", Operation: opeDel, Before: &labelState{ID: 2, Color: "ffffff"}},
+			},
+			problems: []string{"`wontfix` delete: label is attached to issues not in plan (issue num = 3)"},
+		},
+		{
+			name: "invalid operations",
+			opes: []labelPlanOpe{
+				{Name: "help wanted", Operation: opeCrt, After: &labelState{Color: "red"}},
+				{Name: "bug", Operation: opeUpd, After: &labelState{Color: "e03000"}},
+				{Name: "wontfix", Operation: opeDel},
+				{Name: "question", Operation: "rename"},
+			},
+			problems: []string{
+				"`help wanted` create: invalid state after operation",
+				"`bug` update: invalid state before or after operation",
+				"`wontfix` delete: invalid state before operation",
+				"`question` rename: undefined operation",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		problems, err := labelApplier{}.check(labelPlan{Repository: "o/r", Operations: c.opes}, conf, client)
+		if err != nil {
+			t.Errorf("%s: check returns error: %s", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(problems, c.problems) {
+			t.Errorf("%s: problems are %q, expected %q", c.name, problems, c.problems)
+		}
+	}
+}
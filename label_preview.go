@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func labelPreviewCmd() cli.Command {
+	return cli.Command{
+		Name:  "preview",
+		Usage: "render html page with all labels as github shows them",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelPreview{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "label_settings.json",
+				Usage: "you can change label setting json file",
+			},
+			cli.BoolFlag{
+				Name:  "repo-settings, r",
+				Usage: "read label settings from " + repoSettingPath + " in the default branch of the repository",
+			},
+			cli.StringFlag{
+				Name:  "out, o",
+				Value: "-",
+				Usage: "output html file (\"-\" means stdout)",
+			},
+		},
+	}
+}
+
+type labelPreview struct {
+	Out io.Writer
+}
+
+// defaultMinContrast is the contrast ratio for normal text in WCAG 2.0 level AA.
+const defaultMinContrast = 4.5
+
+type previewLabel struct {
+	Name, Color, TextColor, Desc string
+	Contrast                     float64
+	LowContrast                  bool
+}
+
+func (l labelPreview) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	setting, err := label{}.ReadSettings(labelSettingFile(c, conf), client)
+	if err != nil {
+		return err
+	}
+
+	minContrast := defaultMinContrast
+	if setting.MinContrast != nil {
+		minContrast = *setting.MinContrast
+	}
+
+	labels := []previewLabel{}
+	for _, v := range setting.Labels {
+		ratio := contrastRatio(v.Color)
+		labels = append(labels, previewLabel{
+			Name:        v.Name,
+			Color:       v.Color,
+			TextColor:   textColor(v.Color),
+			Desc:        v.Desc,
+			Contrast:    ratio,
+			LowContrast: ratio < minContrast,
+		})
+	}
+
+	dest := c.String("out")
+	out := l.Out
+	buf := &bytes.Buffer{}
+	if dest != "-" {
+		out = buf
+	}
+
+	err = previewTmpl.Execute(out, struct {
+		Title       string
+		Labels      []previewLabel
+		MinContrast float64
+	}{
+		Title:       fmt.Sprintf("Labels for %s/%s", *conf.User, *conf.Repo),
+		Labels:      labels,
+		MinContrast: minContrast,
+	})
+	if err != nil {
+		return err
+	}
+
+	if dest != "-" {
+		if err := writeFileAtomic(dest, buf.Bytes()); err != nil {
+			return fmt.Errorf("unable to create output file (%s)", dest)
+		}
+	}
+
+	return nil
+}
+
+var previewTmpl = template.Must(template.New("preview").Funcs(template.FuncMap{
+	"css": func(s string) template.CSS { return template.CSS(s) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; margin: 32px; }
+table { border-collapse: collapse; }
+td, th { padding: 8px 16px; border-bottom: 1px solid #e1e4e8; text-align: left; }
+.label { display: inline-block; padding: 0 10px; border-radius: 2em; font-size: 12px; font-weight: 500; line-height: 22px; }
+.low { color: #cb2431; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<tr><th>Label</th><th>Color</th><th>Description</th><th>Contrast</th></tr>
+{{- range .Labels}}
+<tr>
+<td><span class="label" style="{{css (printf "background-color: #%s; color: #%s" .Color .TextColor)}}">{{.Name}}</span></td>
+<td>#{{.Color}}</td>
+<td>{{.Desc}}</td>
+<td{{if .LowContrast}} class="low"{{end}}>{{printf "%.2f" .Contrast}}{{if .LowContrast}} (lower than {{$.MinContrast}}){{end}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
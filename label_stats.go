@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func labelStatsCmd() cli.Command {
+	return cli.Command{
+		Name:  "stats",
+		Usage: "output usage of each label in issues and pull requests",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelStats{Out: c.App.Writer})
+		},
+	}
+}
+
+type labelStats struct {
+	Out io.Writer
+}
+
+type labelUsage struct {
+	Name                     string
+	OpenIssues, ClosedIssues int
+	OpenPRs, ClosedPRs       int
+	LastApplied              *time.Time
+	closedCount              int
+	closeDuration            time.Duration
+}
+
+// used reports whether the label is attached to any issues or pull requests, or has been applied.
+func (u labelUsage) used() bool {
+	return u.OpenIssues+u.ClosedIssues+u.OpenPRs+u.ClosedPRs > 0 || u.LastApplied != nil
+}
+
+// avgTimeToClose returns the average time to close the issues, and false if there are no closed issues.
+func (u labelUsage) avgTimeToClose() (time.Duration, bool) {
+	if u.closedCount == 0 {
+		return 0, false
+	}
+	return u.closeDuration / time.Duration(u.closedCount), true
+}
+
+func (l labelStats) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	labels, err := listLabels(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	issues, err := issue{}.listIssues(client, *conf.User, *conf.Repo, "all")
+	if err != nil {
+		return err
+	}
+
+	events, err := listRepositoryEvents(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	usages := l.aggregate(labels, issues, events)
+
+	// output
+	fmt.Fprintf(l.Out, "# Label stats for `%s/%s`\n", *conf.User, *conf.Repo)
+
+	maxLen := 0
+	for _, v := range usages {
+		if len(v.Name) > maxLen {
+			maxLen = len(v.Name)
+		}
+	}
+
+	unused := []string{}
+	fmt.Fprintln(l.Out, "  * usage (issues=open/closed, prs=open/closed)")
+	for _, v := range usages {
+		if !v.used() {
+			unused = append(unused, v.Name)
+			continue
+		}
+		lastApplied := "-"
+		if v.LastApplied != nil {
+			lastApplied = v.LastApplied.Format("2006-01-02")
+		}
+		avg := "-"
+		if d, ok := v.avgTimeToClose(); ok {
+			avg = fmt.Sprintf("%.1f days", d.Hours()/24)
+		}
+		fmt.Fprintf(l.Out, "    %s issues=%d/%d, prs=%d/%d, last applied=%s, avg time to close=%s\n",
+			pad("`"+v.Name+"`", maxLen+2), v.OpenIssues, v.ClosedIssues, v.OpenPRs, v.ClosedPRs, lastApplied, avg)
+	}
+	if since, ok := l.eventsSince(events); ok {
+		fmt.Fprintf(l.Out, "    (last applied is based on the issue events since %s, github returns only recent events)\n", since.Format("2006-01-02"))
+	} else {
+		fmt.Fprintln(l.Out, "    (last applied is unknown, github returns no recent issue events)")
+	}
+	fmt.Fprintln(l.Out, "")
+
+	fmt.Fprintln(l.Out, "  * unused labels")
+	if len(unused) == 0 {
+		fmt.Fprintln(l.Out, "    there are no unused labels")
+	}
+	for _, v := range unused {
+		fmt.Fprintf(l.Out, "    `%s`\n", v)
+	}
+
+	return nil
+}
+
+// aggregate returns usage of the labels sorted by name.
+func (l labelStats) aggregate(labels []*github.Label, issues []*github.Issue, events []*github.IssueEvent) []labelUsage {
+
+	usageMap := make(map[string]*labelUsage)
+	for _, v := range labels {
+		usageMap[v.GetName()] = &labelUsage{Name: v.GetName()}
+	}
+
+	for _, is := range issues {
+		for _, lb := range is.Labels {
+			u, ok := usageMap[lb.GetName()]
+			if !ok {
+				continue
+			}
+			closed := is.GetState() == "closed"
+			switch {
+			case is.IsPullRequest() && closed:
+				u.ClosedPRs++
+			case is.IsPullRequest():
+				u.OpenPRs++
+			case closed:
+				u.ClosedIssues++
+			default:
+				u.OpenIssues++
+			}
+			if !is.IsPullRequest() && closed && is.ClosedAt != nil && is.CreatedAt != nil {
+				u.closedCount++
+				u.closeDuration += is.ClosedAt.Sub(*is.CreatedAt)
+			}
+		}
+	}
+
+	for _, ev := range events {
+		if ev.GetEvent() != "labeled" || ev.Label == nil || ev.CreatedAt == nil {
+			continue
+		}
+		u, ok := usageMap[ev.Label.GetName()]
+		if !ok {
+			continue
+		}
+		if u.LastApplied == nil || ev.CreatedAt.After(*u.LastApplied) {
+			t := *ev.CreatedAt
+			u.LastApplied = &t
+		}
+	}
+
+	usages := []labelUsage{}
+	for _, v := range usageMap {
+		usages = append(usages, *v)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Name < usages[j].Name
+	})
+
+	return usages
+}
+
+// eventsSince returns the time of the oldest event, and false if there are no events.
+// ListRepositoryEvents covers only recent events, so labels applied before it are not counted in last applied.
+func (l labelStats) eventsSince(events []*github.IssueEvent) (time.Time, bool) {
+	var since time.Time
+	for _, ev := range events {
+		if ev.CreatedAt != nil && (since.IsZero() || ev.CreatedAt.Before(since)) {
+			since = *ev.CreatedAt
+		}
+	}
+	return since, !since.IsZero()
+}
+
+// listRepositoryEvents returns the issue events of the repository which github returns.
+// They are only recent events, not all events since the repository was created.
+func listRepositoryEvents(client *github.Client, owner, repo string) ([]*github.IssueEvent, error) {
+
+	opt := &github.ListOptions{Page: 1, PerPage: 100}
+
+	var events []*github.IssueEvent
+	for {
+		list, resp, err := client.Issues.ListRepositoryEvents(context.Background(), owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return events, nil
+}
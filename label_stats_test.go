@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestLabelStatsAggregate(t *testing.T) {
+
+	day := func(d int) *time.Time {
+		t := time.Date(2018, 9, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	closed := func(is *github.Issue, created, closed int) *github.Issue {
+		is.State = github.String("closed")
+		is.CreatedAt, is.ClosedAt = day(created), day(closed)
+		return is
+	}
+	pr := func(is *github.Issue) *github.Issue {
+		is.PullRequestLinks = &github.PullRequestLinks{}
+		return is
+	}
+	event := func(name, label string, d int) *github.IssueEvent {
+		return &github.IssueEvent{Event: github.String(name), Label: &github.Label{Name: github.String(label)}, CreatedAt: day(d)}
+	}
+
+	labels := []*github.Label{
+		{Name: github.String("urgent")},
+		{Name: github.String("bug")},
+		{Name: github.String("wontfix")},
+		{Name: github.String("question")},
+	}
+	issues := []*github.Issue{
+		newTestIssue(1, []string{"bug", "urgent"}, nil),
+		closed(newTestIssue(2, []string{"bug"}, nil), 1, 3),
+		closed(newTestIssue(3, []string{"bug", "undefined"}, nil), 2, 8),
+		pr(newTestIssue(4, []string{"bug"}, nil)),
+		pr(closed(newTestIssue(5, []string{"urgent"}, nil), 1, 20)),
+		closed(newTestIssue(6, []string{"urgent"}, nil), 5, 5),
+	}
+	events := []*github.IssueEvent{
+		event("labeled", "bug", 10),
+		event("labeled", "bug", 12),
+		event("labeled", "bug", 11),
+		event("unlabeled", "urgent", 15),
+		event("labeled", "question", 9),
+		event("labeled", "undefined", 9),
+		{Event: github.String("labeled"), CreatedAt: day(9)},
+	}
+
+	expected := []labelUsage{
+		{Name: "bug", OpenIssues: 1, ClosedIssues: 2, OpenPRs: 1, LastApplied: day(12), closedCount: 2, closeDuration: 8 * 24 * time.Hour},
+		{Name: "question", LastApplied: day(9)},
+		{Name: "urgent", OpenIssues: 1, ClosedIssues: 1, ClosedPRs: 1, closedCount: 1},
+		{Name: "wontfix"},
+	}
+
+	usages := labelStats{}.aggregate(labels, issues, events)
+	if !reflect.DeepEqual(usages, expected) {
+		t.Fatalf("usages are %+v, expected %+v", usages, expected)
+	}
+
+	for i, used := range []bool{true, true, true, false} {
+		if usages[i].used() != used {
+			t.Errorf("%s: used is %t, expected %t", usages[i].Name, usages[i].used(), used)
+		}
+	}
+	if d, ok := usages[0].avgTimeToClose(); !ok || d != 4*24*time.Hour {
+		t.Errorf("avg time to close of bug is %s (%t)", d, ok)
+	}
+	if _, ok := usages[3].avgTimeToClose(); ok {
+		t.Error("avg time to close of wontfix is returned")
+	}
+
+	if since, ok := (labelStats{}).eventsSince(events); !ok || !since.Equal(*day(9)) {
+		t.Errorf("events since %s (%t), expected %s", since, ok, day(9))
+	}
+	if _, ok := (labelStats{}).eventsSince(nil); ok {
+		t.Error("events since is returned without events")
+	}
+}
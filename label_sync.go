@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func labelSyncCmd() cli.Command {
+	return cli.Command{
+		Name:  "sync",
+		Usage: "copy labels of another repository as the source of truth",
+		Action: func(c *cli.Context) error {
+			return action(c, &labelSync{In: os.Stdin, Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "from-repo",
+				Usage: "source repository (owner/repo)",
+			},
+			cli.BoolFlag{
+				Name:  "update, u",
+				Usage: "if this option is set, send update request to github",
+			},
+			cli.StringFlag{
+				Name:  "strategy, s",
+				Usage: "strategy for labels to delete which are attached to issues (strip, migrate-to:<label> or keep)",
+			},
+			cli.BoolFlag{
+				Name:  "interactive, i",
+				Usage: "review each operation and send update request of the selected operations only",
+			},
+		},
+	}
+}
+
+type labelSync struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+func (l labelSync) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	owner, repo, err := l.parseRepo(c.String("from-repo"))
+	if err != nil {
+		return err
+	}
+
+	setting, err := l.readLabels(client, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(l.Out, "# Label sync from `%s/%s`\n", owner, repo)
+	return label{In: l.In, Out: l.Out}.apply(c, conf, client, setting)
+}
+
+func (l labelSync) parseRepo(str string) (string, string, error) {
+	if str == "" {
+		return "", "", fmt.Errorf("source repository is required (--from-repo owner/repo)")
+	}
+	parts := strings.Split(str, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid source repository, must be owner/repo (%s)", str)
+	}
+	return parts[0], parts[1], nil
+}
+
+// readLabels returns the labels of the repository as the label settings.
+func (l labelSync) readLabels(client *github.Client, owner, repo string) (*labelSetting, error) {
+
+	labels, err := listLabels(client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := &labelSetting{LabelMap: make(map[string]labelItem)}
+	for _, v := range labels {
+		// TODO github.Label doesn't have Description in v15.0.0, so don't copy it...
+		setting.Labels = append(setting.Labels, labelDef{Name: v.GetName(), Color: v.GetColor()})
+		setting.LabelMap[v.GetName()] = labelItem{Color: v.GetColor()}
+	}
+
+	if len(setting.Labels) == 0 {
+		return nil, fmt.Errorf("there are no labels in source repository (%s/%s)", owner, repo)
+	}
+
+	return setting, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLabelReplay(t *testing.T) {
+
+	expected := "# Label settings for `octo-org/octo-repo`\n" +
+		"  * label settings\n" +
+		"    `urgent`: update (color=\"ff3000\" -> \"ff3000\", desc=\"Priority is urgent\")\n" +
+		"    `major`: update (color=\"fbca04\" -> \"fbca04\", desc=\"Priority is major\")\n" +
+		"    `minor`: update (color=\"5ecc36\" -> \"5ecc36\", desc=\"Priority is minor\")\n" +
+		"    `pending`: update (color=\"2d86ee\" -> \"2d86ee\", desc=\"Priority is pending\")\n" +
+		"    `bug`: update (color=\"d73a4a\" -> \"e03000\", desc=\"Something isn't working\")\n" +
+		"    `duplicate`: create (color=\"cfd3d7\", desc=\"This issue or pull request already exists\")\n" +
+		"    `size/S`: update (color=\"ededed\" -> \"ededed\", desc=\"Size is small\")\n" +
+		"    `size/L`: update (color=\"5319e7\" -> \"5319e7\", desc=\"Size is large\")\n" +
+		"\n" +
+		"  * replace labels\n" +
+		"    `wontfix`: replace to `pending` (issues=4) and delete\n" +
+		"\n" +
+		"  * ignore labels\n" +
+		"    `area/frontend`\n" +
+		"\n" +
+		"  * delete labels\n" +
+		"    `help wanted`\n" +
+		"\n"
+
+	if out := runReplay(t, "label", "--file", "testdata/label_settings.json"); out != expected {
+		t.Errorf("output is\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestLabelReadSettingsExclusiveGroups(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name   string
+		groups string
+		valid  bool
+	}{
+		{"valid", `[{"name": "priority", "labels": ["urgent", "minor"]}, {"name": "size", "labels": ["size/S", "size/L"]}]`, true},
+		{"undefined label", `[{"name": "priority", "labels": ["urgent", "critical"]}]`, false},
+		{"replaced label", `[{"name": "priority", "labels": ["urgent", "wontfix"]}]`, false},
+		{"ignored label", `[{"name": "area", "labels": ["area/.*"]}]`, false},
+		{"label in several groups", `[{"name": "priority", "labels": ["urgent", "minor"]}, {"name": "other", "labels": ["minor", "size/S"]}]`, false},
+	}
+
+	for _, c := range cases {
+		src := `{
+			"labels": [
+				{"name": "urgent", "color": "ff3000"},
+				{"name": "minor", "color": "5ecc36"},
+				{"name": "size/S", "color": "ededed"},
+				{"name": "size/L", "color": "5319e7"}
+			],
+			"replace": [{"from": "wontfix", "to": "minor"}],
+			"ignore": ["area/*"],
+			"exclusive_groups": ` + c.groups + `
+		}`
+		filename := filepath.Join(dir, "label_settings.json")
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := label{}.ReadSettings(filename, nil)
+		if c.valid && err != nil {
+			t.Errorf("%s: ReadSettings returns error: %s", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: ReadSettings doesn't return error", c.name)
+		}
+	}
+}
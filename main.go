@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/google/go-github/github"
 	"github.com/urfave/cli"
@@ -20,6 +21,12 @@ type subCmd interface {
 var cmdList = []cli.Command{}
 
 func main() {
+	if err := newApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newApp() *cli.App {
 
 	app := cli.NewApp()
 	app.Name = "gitHubManager"
@@ -47,13 +54,21 @@ func main() {
 			Value: "",
 			Usage: "access token to connect your ripository",
 		},
+		cli.StringFlag{
+			Name:  "record",
+			Value: "",
+			Usage: "save all api responses to the directory",
+		},
+		cli.StringFlag{
+			Name:  "replay",
+			Value: "",
+			Usage: "serve api responses from the directory saved with --record instead of github",
+		},
 	}
 
 	app.Commands = cmdList
 
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
-	}
+	return app
 }
 
 func action(c *cli.Context, sc subCmd) error {
@@ -63,6 +78,23 @@ func action(c *cli.Context, sc subCmd) error {
 		return err
 	}
 
+	fixture, err := newFixtureTransport(c.GlobalString("record"), c.GlobalString("replay"))
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(conf, fixture)
+	if err != nil {
+		return err
+	}
+
+	return sc.Run(c, conf, client)
+}
+
+// newClient returns the github client.
+// If fixture is not nil, api responses are recorded or replayed with it.
+func newClient(conf *config, fixture *fixtureTransport) (*github.Client, error) {
+
 	ctx := context.Background()
 	var client *http.Client
 	if conf.Token != nil {
@@ -74,5 +106,15 @@ func action(c *cli.Context, sc subCmd) error {
 		client = http.DefaultClient
 	}
 
-	return sc.Run(c, conf, github.NewClient(client))
+	if fixture != nil {
+		fixture.Base = client.Transport
+		client = &http.Client{Transport: fixture}
+	}
+
+	if conf.BaseURL != nil {
+		uploadURL := strings.Replace(*conf.BaseURL, "/api/v3", "/api/uploads", 1)
+		return github.NewEnterpriseClient(*conf.BaseURL, uploadURL, client)
+	}
+
+	return github.NewClient(client), nil
 }
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+const (
+	metricsDateFormat = "2006-01-02"
+	noLevel           = "None"
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "metrics",
+		Usage: "output lead time, cycle time, throughput and wip of issues grouped by priority level",
+		Action: func(c *cli.Context) error {
+			return action(c, &metrics{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "start date of the range (YYYY-MM-DD, default: 4 weeks ago)",
+			},
+			cli.StringFlag{
+				Name:  "until",
+				Usage: "end date of the range (YYYY-MM-DD, default: today)",
+			},
+			cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "output format (text, csv or json)",
+			},
+		},
+	})
+}
+
+type metrics struct {
+	Out io.Writer
+}
+
+type metricsResult struct {
+	Since  string         `json:"since"`
+	Until  string         `json:"until"`
+	Groups []metricsGroup `json:"groups"`
+}
+
+// metricsGroup is the metrics of issues in a priority level.
+// LeadTime and CycleTime are average days, and nil if there are no closed issues.
+type metricsGroup struct {
+	Level      string       `json:"level"`
+	Closed     int          `json:"closed"`
+	LeadTime   *float64     `json:"lead_time_days"`
+	CycleTime  *float64     `json:"cycle_time_days"`
+	Throughput []countEntry `json:"throughput"`
+	WIP        []countEntry `json:"wip"`
+
+	leadTimes, cycleTimes []time.Duration
+	throughput, wip       map[string]int
+}
+
+type countEntry struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+func (m metrics) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	since, until, err := m.parseRange(c.String("since"), c.String("until"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	format := c.String("format")
+	if format != "text" && format != "csv" && format != "json" {
+		return fmt.Errorf("undefined output format (%s)", format)
+	}
+
+	issues, err := issue{}.listIssues(client, *conf.User, *conf.Repo, "all")
+	if err != nil {
+		return err
+	}
+
+	result, err := m.compute(issues, since, until, conf, func(num int) ([]*github.IssueEvent, error) {
+		return listIssueEvents(client, *conf.User, *conf.Repo, num)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "csv":
+		return m.outputCSV(result)
+	case "json":
+		jsonStr, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(m.Out, string(jsonStr))
+		return nil
+	default:
+		m.outputText(result, conf)
+		return nil
+	}
+}
+
+// parseRange returns the range [since, until) from the dates, which includes the whole day of until.
+func (m metrics) parseRange(sinceStr, untilStr string, now time.Time) (time.Time, time.Time, error) {
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	since, until := today.AddDate(0, 0, -28), today
+
+	var err error
+	if sinceStr != "" {
+		if since, err = time.ParseInLocation(metricsDateFormat, sinceStr, time.Local); err != nil {
+			return since, until, fmt.Errorf("invalid date, must be YYYY-MM-DD (%s)", sinceStr)
+		}
+	}
+	if untilStr != "" {
+		if until, err = time.ParseInLocation(metricsDateFormat, untilStr, time.Local); err != nil {
+			return since, until, fmt.Errorf("invalid date, must be YYYY-MM-DD (%s)", untilStr)
+		}
+	}
+	if since.After(until) {
+		return since, until, fmt.Errorf("since is after until (%s > %s)", since.Format(metricsDateFormat), until.Format(metricsDateFormat))
+	}
+
+	return since, until.AddDate(0, 0, 1), nil
+}
+
+// compute returns the metrics of issues in the range.
+// Pull requests are not included.
+func (m metrics) compute(issues []*github.Issue, since, until time.Time, conf *config,
+	listEvents func(num int) ([]*github.IssueEvent, error)) (*metricsResult, error) {
+
+	groupMap := make(map[string]*metricsGroup)
+	groupNames := append(append([]string{}, levels...), noLevel)
+	for _, v := range groupNames {
+		groupMap[v] = &metricsGroup{Level: v, throughput: make(map[string]int), wip: make(map[string]int)}
+	}
+
+	for _, is := range issues {
+		if is.IsPullRequest() || is.CreatedAt == nil || !is.CreatedAt.Before(until) {
+			continue
+		}
+
+		// events are listed at most once for each issue
+		var cache []*github.IssueEvent
+		fetched := false
+		getEvents := func() ([]*github.IssueEvent, error) {
+			if !fetched {
+				var err error
+				if cache, err = listEvents(is.GetNumber()); err != nil {
+					return nil, err
+				}
+				fetched = true
+			}
+			return cache, nil
+		}
+
+		closedInRange := is.ClosedAt != nil && !is.ClosedAt.Before(since) && is.ClosedAt.Before(until)
+		if closedInRange {
+			g := groupMap[m.level(issueLabels(is), conf)]
+			g.Closed++
+			g.leadTimes = append(g.leadTimes, is.ClosedAt.Sub(*is.CreatedAt))
+			g.throughput[weekOf(*is.ClosedAt).Format(metricsDateFormat)]++
+
+			events, err := getEvents()
+			if err != nil {
+				return nil, err
+			}
+			if start := m.startedAt(events); start != nil && start.Before(*is.ClosedAt) {
+				g.cycleTimes = append(g.cycleTimes, is.ClosedAt.Sub(*start))
+			}
+		}
+
+		// work in progress at the end of the range
+		if is.ClosedAt == nil || !is.ClosedAt.Before(until) || is.GetState() == "open" {
+			events, err := getEvents()
+			if err != nil {
+				return nil, err
+			}
+			assignees, labels, open := m.stateAt(is, events, until)
+			if !open {
+				continue
+			}
+			g := groupMap[m.level(labels, conf)]
+			if len(assignees) == 0 {
+				g.wip[noAssigneesLabel]++
+			}
+			for _, a := range assignees {
+				g.wip[a]++
+			}
+		}
+	}
+
+	result := &metricsResult{
+		Since: since.Format(metricsDateFormat),
+		Until: until.AddDate(0, 0, -1).Format(metricsDateFormat),
+	}
+	for _, v := range groupNames {
+		g := groupMap[v]
+		g.LeadTime = averageDays(g.leadTimes)
+		g.CycleTime = averageDays(g.cycleTimes)
+		g.Throughput = []countEntry{}
+		for w := weekOf(since); w.Before(until); w = w.AddDate(0, 0, 7) {
+			key := w.Format(metricsDateFormat)
+			g.Throughput = append(g.Throughput, countEntry{Key: key, Count: g.throughput[key]})
+		}
+		g.WIP = sortedCounts(g.wip)
+		result.Groups = append(result.Groups, *g)
+	}
+
+	return result, nil
+}
+
+// level returns the highest priority level of the labels.
+func (m metrics) level(labels []string, conf *config) string {
+	for _, lv := range levels {
+		for _, lb := range labels {
+			if existStr(conf.getPriorityLabels(lv), lb) {
+				return lv
+			}
+		}
+	}
+	return noLevel
+}
+
+// stateAt returns the assignees and labels of the issue at t, and false if the issue is closed at t.
+// It rewinds the events at or after t from the current state of the issue.
+func (m metrics) stateAt(is *github.Issue, events []*github.IssueEvent, t time.Time) (assignees, labels []string, open bool) {
+
+	for _, v := range is.Assignees {
+		assignees = append(assignees, v.GetLogin())
+	}
+	labels = issueLabels(is)
+	open = is.GetState() != "closed"
+
+	later := []*github.IssueEvent{}
+	for _, ev := range events {
+		if ev.CreatedAt != nil && !ev.CreatedAt.Before(t) {
+			later = append(later, ev)
+		}
+	}
+	sort.SliceStable(later, func(i, j int) bool {
+		return later[i].CreatedAt.Before(*later[j].CreatedAt)
+	})
+
+	for i := len(later) - 1; i >= 0; i-- {
+		ev := later[i]
+		switch ev.GetEvent() {
+		case "assigned":
+			assignees = removeStr(assignees, ev.GetAssignee().GetLogin())
+		case "unassigned":
+			if login := ev.GetAssignee().GetLogin(); login != "" && !existStr(assignees, login) {
+				assignees = append(assignees, login)
+			}
+		case "labeled":
+			labels = removeStr(labels, ev.GetLabel().GetName())
+		case "unlabeled":
+			if name := ev.GetLabel().GetName(); name != "" && !existStr(labels, name) {
+				labels = append(labels, name)
+			}
+		case "closed":
+			open = true
+		case "reopened":
+			open = false
+		}
+	}
+	sort.Strings(assignees)
+
+	return assignees, labels, open
+}
+
+func issueLabels(is *github.Issue) []string {
+	labels := []string{}
+	for _, v := range is.Labels {
+		labels = append(labels, v.GetName())
+	}
+	return labels
+}
+
+// startedAt returns the time when the issue was first assigned or labeled.
+func (m metrics) startedAt(events []*github.IssueEvent) *time.Time {
+	var start *time.Time
+	for _, ev := range events {
+		if ev.GetEvent() != "assigned" && ev.GetEvent() != "labeled" || ev.CreatedAt == nil {
+			continue
+		}
+		if start == nil || ev.CreatedAt.Before(*start) {
+			start = ev.CreatedAt
+		}
+	}
+	return start
+}
+
+func (m metrics) outputText(result *metricsResult, conf *config) {
+
+	days := func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f days", *v)
+	}
+	counts := func(list []countEntry) string {
+		strs := []string{}
+		for _, v := range list {
+			strs = append(strs, fmt.Sprintf("%s=%d", v.Key, v.Count))
+		}
+		if len(strs) == 0 {
+			return "-"
+		}
+		return concatStr(strs, ", ")
+	}
+
+	fmt.Fprintf(m.Out, "# Metrics for `%s/%s` (%s - %s)\n", *conf.User, *conf.Repo, result.Since, result.Until)
+	for _, g := range result.Groups {
+		fmt.Fprintf(m.Out, "  * %s\n", g.Level)
+		fmt.Fprintf(m.Out, "    closed=%d, lead time=%s, cycle time=%s\n", g.Closed, days(g.LeadTime), days(g.CycleTime))
+		fmt.Fprintf(m.Out, "    throughput per week: %s\n", counts(g.Throughput))
+		fmt.Fprintf(m.Out, "    wip: %s\n", counts(g.WIP))
+	}
+}
+
+// outputCSV outputs the metrics in the form of "level,metric,key,value".
+func (m metrics) outputCSV(result *metricsResult) error {
+
+	w := csv.NewWriter(m.Out)
+	w.Write([]string{"level", "metric", "key", "value"})
+	for _, g := range result.Groups {
+		w.Write([]string{g.Level, "closed", "", strconv.Itoa(g.Closed)})
+		if g.LeadTime != nil {
+			w.Write([]string{g.Level, "lead_time_days", "average", strconv.FormatFloat(*g.LeadTime, 'f', 2, 64)})
+		}
+		if g.CycleTime != nil {
+			w.Write([]string{g.Level, "cycle_time_days", "average", strconv.FormatFloat(*g.CycleTime, 'f', 2, 64)})
+		}
+		for _, v := range g.Throughput {
+			w.Write([]string{g.Level, "throughput", v.Key, strconv.Itoa(v.Count)})
+		}
+		for _, v := range g.WIP {
+			w.Write([]string{g.Level, "wip", v.Key, strconv.Itoa(v.Count)})
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+// weekOf returns the monday of the week of t.
+func weekOf(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+func averageDays(list []time.Duration) *float64 {
+	if len(list) == 0 {
+		return nil
+	}
+	var sum time.Duration
+	for _, v := range list {
+		sum += v
+	}
+	days := sum.Hours() / 24 / float64(len(list))
+	return &days
+}
+
+// sortedCounts returns the counts sorted by count in descending order, then by key.
+func sortedCounts(counts map[string]int) []countEntry {
+	list := []countEntry{}
+	for k, v := range counts {
+		list = append(list, countEntry{Key: k, Count: v})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+// listIssueEvents returns all events of the issue.
+func listIssueEvents(client *github.Client, owner, repo string, number int) ([]*github.IssueEvent, error) {
+
+	opt := &github.ListOptions{Page: 1, PerPage: 100}
+
+	var events []*github.IssueEvent
+	for {
+		list, resp, err := client.Issues.ListIssueEvents(context.Background(), owner, repo, number, opt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return events, nil
+}
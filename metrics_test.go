@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func testDate(month, day int) time.Time {
+	return time.Date(2018, time.Month(month), day, 0, 0, 0, 0, time.Local)
+}
+
+func TestMetricsParseRange(t *testing.T) {
+
+	now := time.Date(2018, 9, 20, 15, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		since, until       string
+		expectedSince      time.Time
+		expectedUntil      time.Time
+		expectedErrMessage string
+	}{
+		{"", "", testDate(8, 23), testDate(9, 21), ""},
+		{"2018-09-03", "2018-09-16", testDate(9, 3), testDate(9, 17), ""},
+		{"2018-09-16", "2018-09-16", testDate(9, 16), testDate(9, 17), ""},
+		{"2018-09-03", "", testDate(9, 3), testDate(9, 21), ""},
+		{"2018/09/03", "", time.Time{}, time.Time{}, "invalid date, must be YYYY-MM-DD (2018/09/03)"},
+		{"", "20180916", time.Time{}, time.Time{}, "invalid date, must be YYYY-MM-DD (20180916)"},
+		{"2018-09-17", "2018-09-16", time.Time{}, time.Time{}, "since is after until (2018-09-17 > 2018-09-16)"},
+	}
+
+	for _, c := range cases {
+		since, until, err := metrics{}.parseRange(c.since, c.until, now)
+		if c.expectedErrMessage != "" {
+			if err == nil || err.Error() != c.expectedErrMessage {
+				t.Errorf("%q, %q: error is %v, expected %q", c.since, c.until, err, c.expectedErrMessage)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q, %q: parseRange returns error: %s", c.since, c.until, err)
+			continue
+		}
+		if !since.Equal(c.expectedSince) || !until.Equal(c.expectedUntil) {
+			t.Errorf("%q, %q: range is [%s, %s), expected [%s, %s)", c.since, c.until, since, until, c.expectedSince, c.expectedUntil)
+		}
+	}
+}
+
+func TestWeekOf(t *testing.T) {
+
+	cases := []struct {
+		t        time.Time
+		expected time.Time
+	}{
+		{testDate(9, 10), testDate(9, 10)},
+		{testDate(9, 11).Add(23 * time.Hour), testDate(9, 10)},
+		{testDate(9, 15), testDate(9, 10)},
+		{testDate(9, 16).Add(23 * time.Hour), testDate(9, 10)},
+		{testDate(9, 17), testDate(9, 17)},
+		{testDate(1, 2), testDate(1, 1)},
+		{time.Date(2019, 1, 1, 0, 0, 0, 0, time.Local), testDate(12, 31)},
+	}
+
+	for _, c := range cases {
+		if w := weekOf(c.t); !w.Equal(c.expected) {
+			t.Errorf("%s: week is %s, expected %s", c.t, w, c.expected)
+		}
+	}
+}
+
+func TestMetricsCompute(t *testing.T) {
+
+	conf := &config{}
+	if err := json.Unmarshal([]byte(`{"label_rule": {"priority": [
+		{"label_name": "urgent", "level": "High"},
+		{"label_name": "major", "level": "Middle"},
+		{"label_name": "minor", "level": "Middle"},
+		{"label_name": "pending", "level": "Low"}
+	]}}`), conf); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(month, day int) *time.Time {
+		d := testDate(month, day)
+		return &d
+	}
+	newIssue := func(num int, labels, assignees []string, created *time.Time, closed *time.Time) *github.Issue {
+		is := newTestIssue(num, labels, assignees)
+		is.CreatedAt, is.ClosedAt, is.State = created, closed, github.String("open")
+		if closed != nil {
+			is.State = github.String("closed")
+		}
+		return is
+	}
+	event := func(name string, month, day int, value string) *github.IssueEvent {
+		ev := &github.IssueEvent{Event: github.String(name), CreatedAt: at(month, day)}
+		switch name {
+		case "assigned", "unassigned":
+			ev.Assignee = &github.User{Login: github.String(value)}
+		case "labeled", "unlabeled":
+			ev.Label = &github.Label{Name: github.String(value)}
+		}
+		return ev
+	}
+
+	pr := newIssue(8, []string{"urgent"}, []string{"alice"}, at(9, 1), nil)
+	pr.PullRequestLinks = &github.PullRequestLinks{}
+
+	issues := []*github.Issue{
+		newIssue(1, []string{"urgent"}, []string{"alice"}, at(9, 1), at(9, 5)),
+		newIssue(2, []string{"major"}, nil, at(9, 4), at(9, 12)),
+		// closed before the range
+		newIssue(3, []string{"major"}, []string{"bob"}, at(8, 1), at(8, 20)),
+		// reassigned and relabeled after the range
+		newIssue(4, []string{"minor"}, []string{"bob"}, at(9, 1), nil),
+		// created after the range
+		newIssue(5, []string{"urgent"}, []string{"alice"}, at(9, 18), nil),
+		// closed after the range
+		newIssue(6, []string{"pending"}, nil, at(9, 2), at(9, 20)),
+		// closed in the range and reopened after it
+		newIssue(7, nil, nil, at(9, 1), at(9, 15)),
+		pr,
+		newIssue(9, nil, []string{"carol"}, at(9, 3), nil),
+	}
+	issues[6].State = github.String("open")
+
+	events := map[int][]*github.IssueEvent{
+		1: {event("assigned", 9, 2, "alice")},
+		2: {event("labeled", 9, 6, "major")},
+		4: {
+			event("labeled", 9, 10, "urgent"),
+			event("assigned", 9, 10, "alice"),
+			event("unassigned", 9, 20, "alice"),
+			event("assigned", 9, 20, "bob"),
+			event("unlabeled", 9, 20, "urgent"),
+			event("labeled", 9, 20, "minor"),
+		},
+		6: {event("closed", 9, 20, "")},
+		7: {event("closed", 9, 15, ""), event("reopened", 9, 19, "")},
+	}
+	listed := map[int]int{}
+	listEvents := func(num int) ([]*github.IssueEvent, error) {
+		listed[num]++
+		return events[num], nil
+	}
+
+	result, err := metrics{}.compute(issues, testDate(9, 3), testDate(9, 17), conf, listEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Since != "2018-09-03" || result.Until != "2018-09-16" {
+		t.Errorf("range is %s - %s", result.Since, result.Until)
+	}
+
+	days := func(d float64) *float64 { return &d }
+	expected := []struct {
+		level      string
+		closed     int
+		leadTime   *float64
+		cycleTime  *float64
+		throughput []countEntry
+		wip        []countEntry
+	}{
+		{"High", 1, days(4), days(3),
+			[]countEntry{{"2018-09-03", 1}, {"2018-09-10", 0}},
+			[]countEntry{{"alice", 1}}},
+		{"Middle", 1, days(8), days(6),
+			[]countEntry{{"2018-09-03", 0}, {"2018-09-10", 1}},
+			[]countEntry{}},
+		{"Low", 0, nil, nil,
+			[]countEntry{{"2018-09-03", 0}, {"2018-09-10", 0}},
+			[]countEntry{{noAssigneesLabel, 1}}},
+		{noLevel, 1, days(14), nil,
+			[]countEntry{{"2018-09-03", 0}, {"2018-09-10", 1}},
+			[]countEntry{{"carol", 1}}},
+	}
+
+	if len(result.Groups) != len(expected) {
+		t.Fatalf("number of groups is %d, expected %d", len(result.Groups), len(expected))
+	}
+	for i, e := range expected {
+		g := result.Groups[i]
+		if g.Level != e.level {
+			t.Errorf("level is %s, expected %s", g.Level, e.level)
+			continue
+		}
+		if g.Closed != e.closed || !reflect.DeepEqual(g.LeadTime, e.leadTime) || !reflect.DeepEqual(g.CycleTime, e.cycleTime) {
+			t.Errorf("%s: closed=%d, lead time=%v, cycle time=%v, expected closed=%d, lead time=%v, cycle time=%v",
+				e.level, g.Closed, g.LeadTime, g.CycleTime, e.closed, e.leadTime, e.cycleTime)
+		}
+		if !reflect.DeepEqual(g.Throughput, e.throughput) {
+			t.Errorf("%s: throughput is %v, expected %v", e.level, g.Throughput, e.throughput)
+		}
+		if !reflect.DeepEqual(g.WIP, e.wip) {
+			t.Errorf("%s: wip is %v, expected %v", e.level, g.WIP, e.wip)
+		}
+	}
+
+	// events are listed only for issues closed in the range or open at the end of the range
+	if expected := map[int]int{1: 1, 2: 1, 4: 1, 6: 1, 7: 1, 9: 1}; !reflect.DeepEqual(listed, expected) {
+		t.Errorf("listed events are %v, expected %v", listed, expected)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "policy",
+		Usage: "check policies for issues or pull requests",
+		Subcommands: []cli.Command{
+			{
+				Name:  "check",
+				Usage: "report violations of policies and exit with non-zero status if any",
+				Action: func(c *cli.Context) error {
+					return action(c, &policyCheck{Out: c.App.Writer})
+				},
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "file, f",
+						Value: "policy.json",
+						Usage: "you can change policy json file",
+					},
+					cli.BoolFlag{
+						Name:  "comment",
+						Usage: "if this option is set, comment the violations on each issue",
+					},
+				},
+			},
+		},
+	})
+}
+
+type policyCheck struct {
+	Out io.Writer
+}
+
+func (p policyCheck) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	pol, err := p.ReadPolicy(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	issues, err := issue{}.getAllIssues(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	assigneeLabels := []string{}
+	for _, level := range pol.RequiredAssigneeLevels {
+		assigneeLabels = append(assigneeLabels, conf.getLabels(level)...)
+	}
+
+	violations := []policyViolation{}
+	for _, is := range issues {
+		if pol.ExcludePullRequests && is.IsPullRequest() {
+			continue
+		}
+		if msgs := pol.check(is, assigneeLabels); len(msgs) > 0 {
+			violations = append(violations, policyViolation{Number: *is.Number, Messages: msgs})
+		}
+	}
+
+	// output
+	fmt.Fprintf(p.Out, "# Policy check for `%s/%s`\n", *conf.User, *conf.Repo)
+	if len(violations) == 0 {
+		fmt.Fprintln(p.Out, "    there are no violations")
+		return nil
+	}
+	for _, v := range violations {
+		fmt.Fprintf(p.Out, "  * %d\n", v.Number)
+		for _, msg := range v.Messages {
+			fmt.Fprintf(p.Out, "    - %s\n", msg)
+		}
+	}
+	fmt.Fprintln(p.Out, "")
+
+	if c.Bool("comment") {
+		fmt.Fprintln(p.Out, "  Comment in progress...")
+		for _, v := range violations {
+			body := policyCommentMarker + "\n" + pol.CommentHeader + "\n\n- " + strings.Join(v.Messages, "\n- ")
+			result, err := p.comment(client, *conf.User, *conf.Repo, v.Number, body)
+			if err != nil {
+				fmt.Fprintf(p.Out, "    %d -> comment fail (err=\"%s\")\n", v.Number, err.Error())
+			} else {
+				fmt.Fprintf(p.Out, "    %d -> comment %s\n", v.Number, result)
+			}
+		}
+		fmt.Fprintln(p.Out, "")
+	}
+
+	return fmt.Errorf("there are policy violations (issues=%d)", len(violations))
+}
+
+// policyCommentMarker is a hidden marker to find the comment posted by policy check.
+const policyCommentMarker = "<!-- githubmgr:policy -->"
+
+// comment updates the comment with the marker on the issue, or creates a new one if not found.
+// It returns the result ("create", "update" or "skip" if the body is not changed).
+func (p policyCheck) comment(client *github.Client, owner, repo string, number int, body string) (string, error) {
+
+	ctx := context.Background()
+
+	comment, err := p.findComment(client, owner, repo, number)
+	if err != nil {
+		return "", err
+	}
+
+	if comment == nil {
+		_, _, err = client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+		return "create", err
+	}
+	if comment.GetBody() == body {
+		return "skip", nil
+	}
+	_, _, err = client.Issues.EditComment(ctx, owner, repo, int(comment.GetID()), &github.IssueComment{Body: &body})
+	return "update", err
+}
+
+// findComment returns the latest comment with the marker on the issue, or nil if not found.
+func (p policyCheck) findComment(client *github.Client, owner, repo string, number int) (*github.IssueComment, error) {
+
+	opt := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+
+	var found *github.IssueComment
+	for {
+		comments, resp, err := client.Issues.ListComments(context.Background(), owner, repo, number, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range comments {
+			if strings.Contains(v.GetBody(), policyCommentMarker) {
+				found = v
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return found, nil
+}
+
+type policy struct {
+	RequiredLabelGroups []struct {
+		Name   string   `json:"name"`
+		Labels []string `json:"labels"`
+	} `json:"required_label_groups"`
+	RequiredMilestone      bool     `json:"required_milestone"`
+	RequiredAssigneeLevels []string `json:"required_assignee_levels"`
+	ExcludePullRequests    bool     `json:"exclude_pull_requests"`
+	CommentHeader          string   `json:"comment_header"`
+}
+
+const defaultPolicyCommentHeader = "This issue violates the following policies."
+
+func (p policyCheck) ReadPolicy(filename string) (*policy, error) {
+
+	pol := &policy{}
+
+	jsonStr, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("not found policy file (%s)", filename)
+	}
+
+	err = json.Unmarshal(jsonStr, pol)
+	if err != nil {
+		return nil, fmt.Errorf("something wrong in policy file (%s)", filename)
+	}
+
+	for _, g := range pol.RequiredLabelGroups {
+		if len(g.Labels) == 0 {
+			return nil, fmt.Errorf("labels of required label group are empty (%s)", g.Name)
+		}
+	}
+	if pol.CommentHeader == "" {
+		pol.CommentHeader = defaultPolicyCommentHeader
+	}
+
+	return pol, nil
+}
+
+// check returns messages of the violations for the issue.
+func (p *policy) check(is *github.Issue, assigneeLabels []string) []string {
+
+	labels := []string{}
+	for _, v := range is.Labels {
+		labels = append(labels, *v.Name)
+	}
+
+	msgs := []string{}
+	for _, g := range p.RequiredLabelGroups {
+		cnt := 0
+		for _, v := range g.Labels {
+			if existStr(labels, v) {
+				cnt++
+			}
+		}
+		if cnt != 1 {
+			msgs = append(msgs, fmt.Sprintf("exactly one `%s` label is required (found %d)", g.Name, cnt))
+		}
+	}
+	if p.RequiredMilestone && is.Milestone == nil {
+		msgs = append(msgs, "milestone is required")
+	}
+	if len(is.Assignees) == 0 {
+		required := []string{}
+		for _, v := range labels {
+			if existStr(assigneeLabels, v) {
+				required = append(required, v)
+			}
+		}
+		if len(required) > 0 {
+			msgs = append(msgs, fmt.Sprintf("assignee is required for issues with %s", concatStrWithBracket(required, ", ", "`")))
+		}
+	}
+
+	return msgs
+}
+
+type policyViolation struct {
+	Number   int
+	Messages []string
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// reportData is the data passed to report templates.
+type reportData struct {
+	User, Repo, Message string
+	TaskCount           int
+	HighIssues          []int
+	ExceptLabels        []string
+	PriorityLabels      []string
+	Changes             *issueChanges
+	// Assignees are in the order of ranking, and (No Assignees) comes last if exists.
+	Assignees []reportAssignee
+	// Priorities are in the order of priority labels, and (No Priority Labels) comes last if exists.
+	Priorities []reportPriority
+	// Mentions are github names of assignees in the order of ranking.
+	Mentions []string
+	// AssigneeWidth and IssueWidth are the widths to align the assignee names and the issue numbers.
+	AssigneeWidth, IssueWidth int
+	Issues                    map[int]*github.Issue
+}
+
+type reportAssignee struct {
+	Name   string
+	Issues []int
+	Score  float64
+}
+
+type reportPriority struct {
+	Label  string
+	Issues []reportIssue
+}
+
+type reportIssue struct {
+	Number    int
+	Assignees []string
+}
+
+func newReportData(iInfo issueInfo, user, repo, message string, exceptLabels, priorityLabels []string) reportData {
+
+	data := reportData{
+		User:           user,
+		Repo:           repo,
+		Message:        message,
+		TaskCount:      len(iInfo.BaseIssues) - iInfo.ExceptIssueCnt,
+		HighIssues:     iInfo.HighIssues,
+		ExceptLabels:   exceptLabels,
+		PriorityLabels: priorityLabels,
+		Changes:        iInfo.Changes,
+		Assignees:      []reportAssignee{},
+		Priorities:     []reportPriority{},
+		Mentions:       iInfo.AssigneeRanking,
+		Issues:         make(map[int]*github.Issue),
+	}
+
+	assignees := iInfo.AssigneeRanking
+	if _, ok := iInfo.AssigneeIssues[noAssigneesLabel]; ok {
+		assignees = append(append([]string{}, assignees...), noAssigneesLabel)
+	}
+	for _, v := range assignees {
+		data.Assignees = append(data.Assignees, reportAssignee{Name: v, Issues: iInfo.AssigneeIssues[v], Score: iInfo.AssigneeScores[v]})
+		if data.AssigneeWidth < len(v) {
+			data.AssigneeWidth = len(v)
+		}
+	}
+
+	for _, v := range append(append([]string{}, priorityLabels...), noPriorityLabel) {
+		issues, ok := iInfo.PriorityIssues[v]
+		if !ok {
+			continue
+		}
+		p := reportPriority{Label: v}
+		for _, num := range issues {
+			p.Issues = append(p.Issues, reportIssue{Number: num, Assignees: iInfo.IssueAssignees[num]})
+		}
+		data.Priorities = append(data.Priorities, p)
+	}
+
+	if len(iInfo.BaseIssues) > 0 {
+		data.IssueWidth = len(strconv.Itoa(*iInfo.BaseIssues[len(iInfo.BaseIssues)-1].Number))
+	}
+	for _, v := range iInfo.BaseIssues {
+		data.Issues[v.GetNumber()] = v
+	}
+
+	return data
+}
+
+// reportFuncs returns helper functions for report templates.
+func reportFuncs(userMap userMappings) map[string]interface{} {
+	return map[string]interface{}{
+		// user returns the name in user_mappings
+		"user": func(name string) string {
+			return userMap.getValue(name)
+		},
+		// users returns the names in user_mappings
+		"users": func(names []string) []string {
+			return userMap.getValues(names)
+		},
+		"pad":  pad,
+		"join": join,
+		// wrap adds front and back to each string
+		"wrap": func(list []string, front, back string) []string {
+			strs := []string{}
+			for _, v := range list {
+				strs = append(strs, front+v+back)
+			}
+			return strs
+		},
+		"nvl": nvl,
+		"date": func(t time.Time) string {
+			return t.Format(snapshotTimeFormat)
+		},
+	}
+}
+
+// pad adds spaces to the end of str to make it the width.
+func pad(str string, width int) string {
+	if len(str) >= width {
+		return str
+	}
+	return str + strings.Repeat(" ", width-len(str))
+}
+
+// join concatenates the elements of the slice with the separator.
+func join(list interface{}, sep string) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(list)
+	}
+	strs := []string{}
+	for i := 0; i < v.Len(); i++ {
+		strs = append(strs, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(strs, sep)
+}
+
+// readReportTemplate returns the template in the file, or the default template if filename is empty.
+func readReportTemplate(filename string, userMap userMappings) (*template.Template, error) {
+
+	tmpl := template.New("report").Funcs(reportFuncs(userMap))
+	if filename == "" {
+		return tmpl.Parse(defaultReportTemplate)
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("not found template file (%s)", filename)
+	}
+	tmpl, err = tmpl.Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("something wrong in template file (%s): %s", filename, err)
+	}
+
+	return tmpl, nil
+}
+
+// defaultReportTemplate is the default layout of the issue report.
+const defaultReportTemplate = "# Issue & PR List for `{{.User}}/{{.Repo}}`" + `
+	task count: {{.TaskCount}}
+	urgent: {{nvl (join .HighIssues ", ")}}
+{{- if .ExceptLabels}}
+	excepts labels: {{nvl (join (wrap .ExceptLabels "` + "`" + `" "` + "`" + `") ", ")}}
+{{- end}}
+{{- with .Changes}}
+
+*Changes{{if not .Since.IsZero}} since {{date .Since}}{{end}}*
+` + "```" + `
+{{- if .Since.IsZero}}
+- there is no previous report
+{{- else}}
+- new: {{nvl (join .NewIssues ", ")}}
+- closed: {{nvl (join .ClosedIssues ", ")}}
+{{- range .Reassigned}}
+- reassigned: {{.Number}} ({{.Before}} -> {{.After}})
+{{- end}}
+{{- range .ReassignedOut}}
+- reassigned out: {{.Number}} ({{.Before}} -> {{.After}})
+{{- end}}
+{{- range .Reprioritized}}
+- priority changed: {{.Number}} ({{.Before}} -> {{.After}})
+{{- end}}
+{{- end}}
+` + "```" + `
+{{- end}}
+{{- if or .PriorityLabels .Changes}}
+
+*Assingee List*
+` + "```" + `
+{{- end}}
+{{- range .Assignees}}
+- {{pad (user .Name) $.AssigneeWidth}} ({{len .Issues}}, score={{.Score}}): {{join .Issues ", "}}
+{{- end}}
+` + "```" + `
+{{- if .PriorityLabels}}
+
+*Priority List*
+` + "```" + `
+{{- range .Priorities}}
+- {{.Label}}
+{{- range .Issues}}
+  - {{pad (print .Number) $.IssueWidth}}: {{if .Assignees}}{{join (users .Assignees) ", "}}{{else}}(No Assignees){{end}}
+{{- end}}
+{{- end}}
+` + "```" + `
+{{- end}}
+
+{{join (wrap (users .Mentions) "@" "") ", "}}
+{{if .Message}}{{.Message}}
+{{end}}`
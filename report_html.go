@@ -0,0 +1,168 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// htmlReportData is the data passed to the html report template.
+type htmlReportData struct {
+	reportData
+	LabelColors map[string]string
+	Generated   time.Time
+}
+
+// outputHTML outputs the report as a self-contained html page.
+// labels are used for the colors of label chips.
+func (i issue) outputHTML(out io.Writer, data reportData, labels []*github.Label, userMap userMappings) error {
+
+	colors := make(map[string]string)
+	for _, v := range labels {
+		if labelColorPtn.MatchString(v.GetColor()) {
+			colors[v.GetName()] = v.GetColor()
+		}
+	}
+
+	funcs := template.FuncMap(reportFuncs(userMap))
+	// chip returns the style of the label chip with the color of the repository label
+	funcs["chip"] = func(name string) template.CSS {
+		color, ok := colors[name]
+		if !ok {
+			color = "ededed"
+		}
+		return template.CSS("background-color: #" + color + "; color: #" + textColor(color))
+	}
+	// labels returns the label names of the issue sorted by name
+	funcs["labels"] = func(is *github.Issue) []string {
+		names := []string{}
+		for _, v := range is.Labels {
+			names = append(names, v.GetName())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	tmpl, err := template.New("html").Funcs(funcs).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(out, htmlReportData{reportData: data, LabelColors: colors, Generated: time.Now()})
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Issue &amp; PR List for {{.User}}/{{.Repo}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; margin: 32px; color: #24292e; }
+a { color: #0366d6; text-decoration: none; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+td, th { padding: 6px 12px; border-bottom: 1px solid #e1e4e8; text-align: left; vertical-align: top; }
+th { cursor: pointer; user-select: none; background: #f6f8fa; }
+th:after { content: " \2195"; color: #959da5; }
+.label { display: inline-block; padding: 0 8px; margin-right: 4px; border-radius: 2em; font-size: 12px; font-weight: 500; line-height: 20px; }
+.summary td { border: none; padding: 2px 12px 2px 0; }
+</style>
+</head>
+<body>
+<h1>Issue &amp; PR List for {{.User}}/{{.Repo}}</h1>
+<table class="summary">
+<tr><td>task count</td><td>{{.TaskCount}}</td></tr>
+<tr><td>urgent</td><td>{{range .HighIssues}}{{with index $.Issues .}}<a href="{{.GetHTMLURL}}" title="{{.GetTitle}}">#{{.GetNumber}}</a> {{end}}{{else}}-{{end}}</td></tr>
+{{- if .ExceptLabels}}
+<tr><td>excepts labels</td><td>{{range .ExceptLabels}}<span class="label" style="{{chip .}}">{{.}}</span>{{end}}</td></tr>
+{{- end}}
+<tr><td>generated</td><td>{{date .Generated}}</td></tr>
+</table>
+{{- with .Changes}}
+
+<h2>Changes{{if not .Since.IsZero}} since {{date .Since}}{{end}}</h2>
+{{- if .Since.IsZero}}
+<p>there is no previous report</p>
+{{- else}}
+<ul>
+<li>new: {{range .NewIssues}}{{with index $.Issues .}}<a href="{{.GetHTMLURL}}">#{{.GetNumber}}</a> {{.GetTitle}}; {{end}}{{else}}-{{end}}</li>
+<li>closed: {{join .ClosedIssues ", "}}{{if not .ClosedIssues}}-{{end}}</li>
+{{- range .Reassigned}}
+<li>reassigned: #{{.Number}} ({{.Before}} -&gt; {{.After}})</li>
+{{- end}}
+{{- range .ReassignedOut}}
+<li>reassigned out: #{{.Number}} ({{.Before}} -&gt; {{.After}})</li>
+{{- end}}
+{{- range .Reprioritized}}
+<li>priority changed: #{{.Number}} ({{.Before}} -&gt; {{.After}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+
+<h2>Assignees</h2>
+<table class="sortable">
+<thead><tr><th>Assignee</th><th>Count</th><th>Score</th><th>Issues</th></tr></thead>
+<tbody>
+{{- range .Assignees}}
+<tr>
+<td>{{user .Name}}</td>
+<td data-value="{{len .Issues}}">{{len .Issues}}</td>
+<td data-value="{{.Score}}">{{.Score}}</td>
+<td>{{range .Issues}}{{with index $.Issues .}}<a href="{{.GetHTMLURL}}" title="{{.GetTitle}}">#{{.GetNumber}}</a> {{end}}{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>Issues</h2>
+<table class="sortable">
+<thead><tr><th>Priority</th><th>Number</th><th>Title</th><th>Labels</th><th>Assignees</th></tr></thead>
+<tbody>
+{{- range $p := .Priorities}}
+{{- range .Issues}}
+{{- with index $.Issues .Number}}
+<tr>
+<td>{{$p.Label}}</td>
+<td data-value="{{.GetNumber}}"><a href="{{.GetHTMLURL}}">#{{.GetNumber}}</a></td>
+<td>{{.GetTitle}}</td>
+<td>{{range labels .}}<span class="label" style="{{chip .}}">{{.}}</span>{{end}}</td>
+<td>{{range .Assignees}}{{user .GetLogin}} {{else}}(No Assignees){{end}}</td>
+</tr>
+{{- end}}
+{{- end}}
+{{- end}}
+</tbody>
+</table>
+{{- if .Message}}
+
+<p>{{.Message}}</p>
+{{- end}}
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, col) {
+    var asc = true;
+    th.addEventListener("click", function () {
+      var tbody = table.tBodies[0];
+      var rows = Array.prototype.slice.call(tbody.rows);
+      var value = function (row) {
+        var cell = row.cells[col];
+        var v = cell.getAttribute("data-value");
+        return v !== null ? parseFloat(v) : cell.textContent.trim().toLowerCase();
+      };
+      rows.sort(function (a, b) {
+        var x = value(a), y = value(b);
+        return (x < y ? -1 : x > y ? 1 : 0) * (asc ? 1 : -1);
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+      asc = !asc;
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+const (
+	scheduleCmdName    = "schedule"
+	outputStdout       = "-"
+	jobRetryInterval   = time.Minute
+	slackWebhookPrefix = "https://hooks.slack.com/"
+	slackPostTimeout   = 30 * time.Second
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:    scheduleCmdName,
+		Aliases: []string{"serve"},
+		Usage:   "run jobs in the config file on a cron schedule",
+		Action: func(c *cli.Context) error {
+			return action(c, &schedule{
+				Out:   c.App.Writer,
+				Log:   log.New(os.Stderr, "", log.LstdFlags),
+				Clock: realClock{},
+				RunCmd: func(args []string, out io.Writer) error {
+					app := newApp()
+					app.Writer = out
+					return app.Run(args)
+				},
+			})
+		},
+	})
+}
+
+// clock is injectable for testing the scheduler.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type schedule struct {
+	Out    io.Writer
+	Log    *log.Logger
+	Clock  clock
+	RunCmd func(args []string, out io.Writer) error
+}
+
+type job struct {
+	Name, Command, Output string
+	Args                  []string
+	Retries               int
+	Cron                  *cronSchedule
+}
+
+func (s schedule) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	jobs, err := s.createJobs(conf)
+	if err != nil {
+		return err
+	}
+
+	globalArgs := s.globalArgs(c)
+
+	fmt.Fprintf(s.Out, "# Schedule for `%s/%s`\n", *conf.User, *conf.Repo)
+	now := s.Clock.Now()
+	for _, j := range jobs {
+		fmt.Fprintf(s.Out, "    `%s`: %s (next=%s)\n", j.Name, j.Command, j.Cron.Next(now).Format(time.RFC3339))
+	}
+
+	for t := now; ; {
+		if t, err = s.runNext(jobs, t, globalArgs); err != nil {
+			return err
+		}
+	}
+}
+
+// runNext waits until the next scheduled time after t, runs the jobs scheduled at the time,
+// and returns the time.
+func (s schedule) runNext(jobs []job, t time.Time, globalArgs []string) (time.Time, error) {
+
+	next := time.Time{}
+	for _, j := range jobs {
+		if n := j.Cron.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	if next.IsZero() {
+		return t, errors.New("there are no jobs to be scheduled")
+	}
+
+	<-s.Clock.After(next.Sub(s.Clock.Now()))
+
+	for _, j := range jobs {
+		if j.Cron.Next(t).Equal(next) {
+			s.runJob(j, globalArgs)
+		}
+	}
+
+	return next, nil
+}
+
+func (s schedule) createJobs(conf *config) ([]job, error) {
+
+	if len(conf.Jobs) == 0 {
+		return nil, errors.New("jobs are not defined in config file")
+	}
+
+	jobs := []job{}
+	for i, v := range conf.Jobs {
+		j := job{
+			Name:   fmt.Sprintf("job%d", i+1),
+			Args:   v.Args,
+			Output: outputStdout,
+		}
+		if v.Name != nil {
+			j.Name = *v.Name
+		}
+		if v.Command == nil || v.Schedule == nil {
+			return nil, fmt.Errorf("command and schedule are mandatory for job (%s)", j.Name)
+		}
+		if err := checkJobCmd(*v.Command); err != nil {
+			return nil, fmt.Errorf("%s (%s: %s)", err, j.Name, *v.Command)
+		}
+		j.Command = *v.Command
+		cron, err := parseCron(*v.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		j.Cron = cron
+		if v.Output != nil {
+			j.Output = *v.Output
+		}
+		if v.Retries != nil {
+			j.Retries = *v.Retries
+		}
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+// globalArgs returns global options to be passed to each job.
+func (s schedule) globalArgs(c *cli.Context) []string {
+	args := []string{c.App.Name}
+	for _, name := range []string{"config", "user", "repo", "token", "record", "replay"} {
+		if v := c.GlobalString(name); v != "" {
+			args = append(args, "--"+name, v)
+		}
+	}
+	return args
+}
+
+// runJob runs the job and outputs the result.
+// Errors are only logged so that the scheduler keeps running.
+func (s schedule) runJob(j job, globalArgs []string) {
+
+	args := append(append(append([]string{}, globalArgs...), j.Command), j.Args...)
+
+	for try := 0; ; try++ {
+		buf := &bytes.Buffer{}
+		err := s.runCmd(args, buf)
+		if err == nil {
+			err = s.output(j.Output, buf.Bytes())
+		}
+		if err == nil {
+			s.Log.Printf("job `%s` success", j.Name)
+			return
+		}
+		if try >= j.Retries {
+			s.Log.Printf("job `%s` fail (err=\"%s\")", j.Name, err.Error())
+			return
+		}
+		s.Log.Printf("job `%s` fail, retry in %s (err=\"%s\")", j.Name, jobRetryInterval, err.Error())
+		<-s.Clock.After(jobRetryInterval)
+	}
+}
+
+func (s schedule) runCmd(args []string, out io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.RunCmd(args, out)
+}
+
+// output writes the result to stdout, a file or a slack incoming webhook.
+func (s schedule) output(dest string, result []byte) error {
+
+	switch {
+	case dest == outputStdout:
+		_, err := s.Out.Write(result)
+		return err
+
+	case strings.HasPrefix(dest, slackWebhookPrefix):
+		body, err := json.Marshal(map[string]string{"text": string(result)})
+		if err != nil {
+			return err
+		}
+		resp, err := slackClient.Post(dest, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("slack returns unexpected status (%s)", resp.Status)
+		}
+		return nil
+
+	default:
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(result)
+		return err
+	}
+}
+
+// slackClient is used to post results, with a timeout not to block the scheduler on a hung endpoint.
+var slackClient = &http.Client{Timeout: slackPostTimeout}
+
+// serverCmdNames are the commands which never return, so they can't be run as jobs.
+var serverCmdNames = []string{scheduleCmdName, webhookCmdName}
+
+// findCmd returns the command which has the name or the alias, or nil if not found.
+func findCmd(name string) *cli.Command {
+	for i := range cmdList {
+		if cmdList[i].HasName(name) {
+			return &cmdList[i]
+		}
+	}
+	return nil
+}
+
+// checkJobCmd returns an error if the command can't be run as a job.
+func checkJobCmd(name string) error {
+	cmd := findCmd(name)
+	if cmd == nil {
+		return errors.New("undefined command")
+	}
+	for _, v := range serverCmdNames {
+		if cmd.HasName(v) {
+			return errors.New("command which never returns can't be run as a job")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock advances the time by the duration instead of waiting.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// fakeRunner records the args of each run and fails the first fails runs.
+type fakeRunner struct {
+	runs  [][]string
+	fails int
+}
+
+func (r *fakeRunner) Run(args []string, out io.Writer) error {
+	r.runs = append(r.runs, args)
+	if len(r.runs) <= r.fails {
+		return errors.New("failure")
+	}
+	_, err := io.WriteString(out, strings.Join(args, " ")+"\n")
+	return err
+}
+
+func newTestSchedule(clk *fakeClock, runner *fakeRunner) (schedule, *bytes.Buffer, *bytes.Buffer) {
+	out, logs := &bytes.Buffer{}, &bytes.Buffer{}
+	return schedule{
+		Out:    out,
+		Log:    log.New(logs, "", 0),
+		Clock:  clk,
+		RunCmd: runner.Run,
+	}, out, logs
+}
+
+func mustParseCron(t *testing.T, expr string) *cronSchedule {
+	t.Helper()
+	s, err := parseCron(expr)
+	if err != nil {
+		t.Fatalf("parseCron(%q) returns error: %s", expr, err)
+	}
+	return s
+}
+
+func TestScheduleRunNext(t *testing.T) {
+
+	start := time.Date(2018, 3, 1, 8, 50, 0, 0, time.UTC)
+	clk := &fakeClock{now: start}
+	runner := &fakeRunner{}
+	s, out, _ := newTestSchedule(clk, runner)
+
+	jobs := []job{
+		{Name: "quarter", Command: "issue", Output: outputStdout, Cron: mustParseCron(t, "*/15 * * * *")},
+		{Name: "morning", Command: "label", Args: []string{"--update"}, Output: outputStdout, Cron: mustParseCron(t, "0 9 * * *")},
+	}
+	globalArgs := []string{"githubmgr", "--config", "config.json"}
+
+	expected := []struct {
+		time time.Time
+		wait time.Duration
+		runs [][]string
+	}{
+		{
+			time: time.Date(2018, 3, 1, 9, 0, 0, 0, time.UTC),
+			wait: 10 * time.Minute,
+			runs: [][]string{
+				{"githubmgr", "--config", "config.json", "issue"},
+				{"githubmgr", "--config", "config.json", "label", "--update"},
+			},
+		},
+		{
+			time: time.Date(2018, 3, 1, 9, 15, 0, 0, time.UTC),
+			wait: 15 * time.Minute,
+			runs: [][]string{
+				{"githubmgr", "--config", "config.json", "issue"},
+			},
+		},
+	}
+
+	now := start
+	for i, e := range expected {
+		runner.runs = nil
+		next, err := s.runNext(jobs, now, globalArgs)
+		if err != nil {
+			t.Fatalf("%d: runNext returns error: %s", i, err)
+		}
+		if !next.Equal(e.time) {
+			t.Errorf("%d: next time is %s, expected %s", i, next, e.time)
+		}
+		if w := clk.waits[len(clk.waits)-1]; w != e.wait {
+			t.Errorf("%d: waited %s, expected %s", i, w, e.wait)
+		}
+		if !reflect.DeepEqual(runner.runs, e.runs) {
+			t.Errorf("%d: runs are %v, expected %v", i, runner.runs, e.runs)
+		}
+		now = next
+	}
+
+	if !strings.Contains(out.String(), "githubmgr --config config.json label --update\n") {
+		t.Errorf("job output is not written to stdout: %q", out.String())
+	}
+}
+
+func TestScheduleRunNextNoJobs(t *testing.T) {
+
+	clk := &fakeClock{now: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)}
+	s, _, _ := newTestSchedule(clk, &fakeRunner{})
+
+	// February 30 never comes
+	jobs := []job{{Name: "never", Command: "issue", Cron: mustParseCron(t, "0 0 30 2 *")}}
+	if _, err := s.runNext(jobs, clk.now, nil); err == nil {
+		t.Error("runNext doesn't return error for jobs never scheduled")
+	}
+	if len(clk.waits) != 0 {
+		t.Errorf("runNext waits for jobs never scheduled: %v", clk.waits)
+	}
+}
+
+func TestScheduleRetry(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		retries int
+		fails   int
+		runs    int
+		waits   int
+		log     string
+	}{
+		{name: "success", retries: 2, fails: 0, runs: 1, waits: 0, log: "job `j` success"},
+		{name: "success after retry", retries: 2, fails: 2, runs: 3, waits: 2, log: "job `j` success"},
+		{name: "fail", retries: 1, fails: 5, runs: 2, waits: 1, log: "job `j` fail (err=\"failure\")"},
+		{name: "no retry", retries: 0, fails: 1, runs: 1, waits: 0, log: "job `j` fail (err=\"failure\")"},
+	}
+
+	for _, c := range cases {
+		clk := &fakeClock{now: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)}
+		runner := &fakeRunner{fails: c.fails}
+		s, _, logs := newTestSchedule(clk, runner)
+
+		s.runJob(job{Name: "j", Command: "issue", Output: outputStdout, Retries: c.retries}, []string{"githubmgr"})
+
+		if len(runner.runs) != c.runs {
+			t.Errorf("%s: runs=%d, expected %d", c.name, len(runner.runs), c.runs)
+		}
+		if len(clk.waits) != c.waits {
+			t.Errorf("%s: waits=%d, expected %d", c.name, len(clk.waits), c.waits)
+		}
+		for _, w := range clk.waits {
+			if w != jobRetryInterval {
+				t.Errorf("%s: waited %s before retry, expected %s", c.name, w, jobRetryInterval)
+			}
+		}
+		lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+		if last := lines[len(lines)-1]; last != c.log {
+			t.Errorf("%s: last log is %q, expected %q", c.name, last, c.log)
+		}
+	}
+}
+
+func TestScheduleRunJobPanic(t *testing.T) {
+
+	clk := &fakeClock{now: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)}
+	s, _, logs := newTestSchedule(clk, &fakeRunner{})
+	s.RunCmd = func(args []string, out io.Writer) error {
+		panic("boom")
+	}
+
+	s.runJob(job{Name: "j", Command: "issue", Output: outputStdout}, nil)
+
+	if !strings.Contains(logs.String(), "panic: boom") {
+		t.Errorf("panic of job is not logged: %q", logs.String())
+	}
+}
+
+func TestScheduleCreateJobs(t *testing.T) {
+
+	cases := []struct {
+		command string
+		valid   bool
+	}{
+		{"issue", true},
+		{"label", true},
+		{"schedule", false},
+		{"serve", false},
+		{"webhook", false},
+		{"undefined", false},
+	}
+
+	for _, c := range cases {
+		conf := &config{}
+		src := `{"jobs": [{"command": "` + c.command + `", "schedule": "0 9 * * *"}]}`
+		if err := json.Unmarshal([]byte(src), conf); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := schedule{}.createJobs(conf)
+		if c.valid && err != nil {
+			t.Errorf("%s: createJobs returns error: %s", c.command, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: createJobs doesn't return error", c.command)
+		}
+
+		problems := conf.validate()
+		if c.valid && len(problems) > 0 {
+			t.Errorf("%s: validate returns problems: %v", c.command, problems)
+		}
+		if !c.valid && len(problems) != 1 {
+			t.Errorf("%s: validate returns problems: %v, expected 1 problem", c.command, problems)
+		}
+	}
+}
+
+func TestScheduleCreateJobsDefaults(t *testing.T) {
+
+	conf := &config{}
+	src := `{"jobs": [
+		{"command": "issue", "schedule": "0 9 * * 1-5"},
+		{"name": "sync", "command": "label", "args": ["--update"], "schedule": "0 0 * * *", "output": "out.log", "retries": 3}
+	]}`
+	if err := json.Unmarshal([]byte(src), conf); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := schedule{}.createJobs(conf)
+	if err != nil {
+		t.Fatalf("createJobs returns error: %s", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("number of jobs is %d, expected 2", len(jobs))
+	}
+	if j := jobs[0]; j.Name != "job1" || j.Output != outputStdout || j.Retries != 0 {
+		t.Errorf("default values of job are wrong: %+v", j)
+	}
+	if j := jobs[1]; j.Name != "sync" || j.Output != "out.log" || j.Retries != 3 || !reflect.DeepEqual(j.Args, []string{"--update"}) {
+		t.Errorf("values of job are wrong: %+v", j)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	// snapshotFileName is the default snapshot file in the user-level directory.
+	snapshotFileName = "snapshots.jsonl"
+	// defaultRetentionDays is the default number of days to keep snapshots.
+	defaultRetentionDays = 90
+)
+
+// issueSnapshot is the state of issues at the time of an issue report.
+type issueSnapshot struct {
+	Key            string             `json:"key"`
+	Time           time.Time          `json:"time"`
+	Issues         []snapshotIssue    `json:"issues"`
+	HighIssues     []int              `json:"high_issues"`
+	AssigneeIssues map[string][]int   `json:"assignee_issues"`
+	AssigneeScores map[string]float64 `json:"assignee_scores"`
+	PriorityIssues map[string][]int   `json:"priority_issues"`
+}
+
+type snapshotIssue struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+}
+
+// snapshotKey returns the key of snapshots for the repository and the team.
+func snapshotKey(conf *config, team string) string {
+	key := fmt.Sprintf("%s/%s", *conf.User, *conf.Repo)
+	if team != "" {
+		key += "#" + team
+	}
+	return key
+}
+
+// newIssueSnapshot creates a snapshot from the issues and the issue info,
+// which should be created with all priority labels and without except labels.
+func newIssueSnapshot(key string, t time.Time, issues []*github.Issue, iInfo issueInfo) issueSnapshot {
+
+	snapshot := issueSnapshot{
+		Key:            key,
+		Time:           t,
+		Issues:         []snapshotIssue{},
+		HighIssues:     iInfo.HighIssues,
+		AssigneeIssues: iInfo.AssigneeIssues,
+		AssigneeScores: iInfo.AssigneeScores,
+		PriorityIssues: iInfo.PriorityIssues,
+	}
+	for _, is := range issues {
+		v := snapshotIssue{Number: is.GetNumber(), Title: is.GetTitle(), Labels: []string{}, Assignees: []string{}}
+		for _, lb := range is.Labels {
+			v.Labels = append(v.Labels, lb.GetName())
+		}
+		for _, a := range is.Assignees {
+			v.Assignees = append(v.Assignees, a.GetLogin())
+		}
+		snapshot.Issues = append(snapshot.Issues, v)
+	}
+
+	return snapshot
+}
+
+// snapshotStore is a file which has a snapshot in json per line.
+// Snapshots older than Retention are removed when a snapshot is saved, and all snapshots are kept if it is 0.
+type snapshotStore struct {
+	Path      string
+	Retention time.Duration
+}
+
+// newSnapshotStore returns the store of snapshot_file in config, or the default file in the user-level directory.
+func newSnapshotStore(conf *config) (snapshotStore, error) {
+	days := defaultRetentionDays
+	if conf.Retention != nil {
+		days = *conf.Retention
+	}
+	store := snapshotStore{Retention: time.Duration(days) * 24 * time.Hour}
+
+	if conf.Snapshot != nil {
+		store.Path = *conf.Snapshot
+		return store, nil
+	}
+	dir, err := userConfigPath()
+	if err != nil {
+		return snapshotStore{}, fmt.Errorf("unable to find snapshot directory (%s)", err)
+	}
+	store.Path = filepath.Join(dir, snapshotFileName)
+	return store, nil
+}
+
+// Save appends the snapshot to the file, and removes the snapshots older than the retention from the time of the snapshot.
+func (s snapshotStore) Save(snapshot issueSnapshot) error {
+
+	if err := s.append(snapshot); err != nil {
+		return err
+	}
+	if s.Retention <= 0 {
+		return nil
+	}
+	return s.prune(snapshot.Time.Add(-s.Retention))
+}
+
+func (s snapshotStore) append(snapshot issueSnapshot) error {
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// prune removes the snapshots of all keys saved before the time.
+// Lines which can't be parsed are kept, so that Load reports them.
+func (s snapshotStore) prune(before time.Time) error {
+
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return err
+	}
+
+	kept, removed := [][]byte{}, false
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		snapshot := struct {
+			Time time.Time `json:"time"`
+		}{}
+		if err := json.Unmarshal(line, &snapshot); err == nil && snapshot.Time.Before(before) {
+			removed = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if !removed {
+		return nil
+	}
+
+	return writeFileAtomic(s.Path, append(bytes.Join(kept, []byte("\n")), '\n'))
+}
+
+// Load returns the snapshots of the key in chronological order.
+// It returns no snapshots if the file doesn't exist.
+func (s snapshotStore) Load(key string) ([]issueSnapshot, error) {
+
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snapshots := []issueSnapshot{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		snapshot := issueSnapshot{}
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("something wrong in snapshot file (%s:%d)", s.Path, lineNum)
+		}
+		if snapshot.Key == key {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+
+	return snapshots, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotStoreRetention(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	base := time.Date(2018, 9, 1, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return base.AddDate(0, 0, d) }
+
+	times := func(store snapshotStore, key string) []time.Time {
+		snapshots, err := store.Load(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := []time.Time{}
+		for _, v := range snapshots {
+			list = append(list, v.Time)
+		}
+		return list
+	}
+
+	cases := []struct {
+		name      string
+		retention time.Duration
+		saves     []issueSnapshot
+		expectedA []time.Time
+		expectedB []time.Time
+	}{
+		{
+			name:      "keep all",
+			retention: 0,
+			saves:     []issueSnapshot{{Key: "a", Time: day(0)}, {Key: "b", Time: day(1)}, {Key: "a", Time: day(100)}},
+			expectedA: []time.Time{day(0), day(100)},
+			expectedB: []time.Time{day(1)},
+		},
+		{
+			// old snapshots of all keys are removed
+			name:      "remove old snapshots",
+			retention: 10 * 24 * time.Hour,
+			saves:     []issueSnapshot{{Key: "a", Time: day(0)}, {Key: "b", Time: day(4)}, {Key: "a", Time: day(10)}, {Key: "a", Time: day(15)}},
+			expectedA: []time.Time{day(10), day(15)},
+			expectedB: []time.Time{},
+		},
+		{
+			name:      "within retention",
+			retention: 10 * 24 * time.Hour,
+			saves:     []issueSnapshot{{Key: "a", Time: day(0)}, {Key: "b", Time: day(5)}, {Key: "a", Time: day(10)}},
+			expectedA: []time.Time{day(0), day(10)},
+			expectedB: []time.Time{day(5)},
+		},
+	}
+
+	for i, c := range cases {
+		store := snapshotStore{Path: filepath.Join(dir, "snapshots", fmt.Sprintf("%d.jsonl", i)), Retention: c.retention}
+		for _, v := range c.saves {
+			if err := store.Save(v); err != nil {
+				t.Fatalf("%s: Save returns error: %s", c.name, err)
+			}
+		}
+		if list := times(store, "a"); !reflect.DeepEqual(list, c.expectedA) {
+			t.Errorf("%s: snapshots of a are %v, expected %v", c.name, list, c.expectedA)
+		}
+		if list := times(store, "b"); !reflect.DeepEqual(list, c.expectedB) {
+			t.Errorf("%s: snapshots of b are %v, expected %v", c.name, list, c.expectedB)
+		}
+	}
+}
+
+func TestNewSnapshotStore(t *testing.T) {
+
+	path, days := "snapshots.jsonl", 30
+	cases := []struct {
+		conf     *config
+		expected time.Duration
+	}{
+		{&config{Snapshot: &path}, defaultRetentionDays * 24 * time.Hour},
+		{&config{Snapshot: &path, Retention: &days}, 30 * 24 * time.Hour},
+	}
+
+	for _, c := range cases {
+		store, err := newSnapshotStore(c.conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if store.Path != path || store.Retention != c.expected {
+			t.Errorf("store is %+v, expected retention %s", store, c.expected)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const slackNamePlaceholder = "{github_name}"
+
+// teamMembers is a map of team name and the members of the team.
+type teamMembers map[string][]string
+
+// getTeamMembers resolves the members of the named teams in the config file via the teams API.
+// Members who are not in user mappings are mapped with the slack name pattern of the team.
+func getTeamMembers(client *github.Client, conf *config, names ...string) (teamMembers, error) {
+
+	for _, name := range names {
+		if !existStr(conf.getTeamNames(), name) {
+			return nil, fmt.Errorf("undefined team in config file (%s)", name)
+		}
+	}
+
+	members := make(teamMembers)
+	orgTeams := make(map[string][]*github.Team)
+
+	for _, team := range conf.Teams {
+
+		if !existStr(names, *team.Name) {
+			continue
+		}
+
+		org := *conf.User
+		if team.Organization != nil {
+			org = *team.Organization
+		}
+
+		if _, ok := orgTeams[org]; !ok {
+			teams, err := listTeams(client, org)
+			if err != nil {
+				return nil, err
+			}
+			orgTeams[org] = teams
+		}
+
+		var teamID *int64
+		for _, t := range orgTeams[org] {
+			if *t.Slug == *team.Name || *t.Name == *team.Name {
+				teamID = t.ID
+				break
+			}
+		}
+		if teamID == nil {
+			return nil, fmt.Errorf("not found team in organization (%s/%s)", org, *team.Name)
+		}
+
+		logins, err := listTeamMembers(client, *teamID)
+		if err != nil {
+			return nil, err
+		}
+		members[*team.Name] = logins
+
+		if team.SlackNamePattern == nil {
+			continue
+		}
+		for _, login := range logins {
+			if _, ok := conf.UserMappings[login]; !ok {
+				conf.UserMappings[login] = strings.Replace(*team.SlackNamePattern, slackNamePlaceholder, login, -1)
+			}
+		}
+	}
+
+	return members, nil
+}
+
+func listTeams(client *github.Client, org string) ([]*github.Team, error) {
+
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+
+	var allTeams []*github.Team
+	for {
+		teams, resp, err := client.Organizations.ListTeams(context.Background(), org, opt)
+		if err != nil {
+			return nil, err
+		}
+		allTeams = append(allTeams, teams...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return allTeams, nil
+}
+
+func listTeamMembers(client *github.Client, teamID int64) ([]string, error) {
+
+	opt := &github.OrganizationListTeamMembersOptions{
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+
+	var logins []string
+	for {
+		users, resp, err := client.Organizations.ListTeamMembers(context.Background(), teamID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			logins = append(logins, *u.Login)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return logins, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "trend",
+		Usage: "compare snapshots saved by issue command",
+		Action: func(c *cli.Context) error {
+			return action(c, &trend{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "team",
+				Value: "",
+				Usage: "compare snapshots of the team",
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "compare snapshots since the date (YYYY-MM-DD, default: the oldest snapshot)",
+			},
+			cli.IntFlag{
+				Name:  "history",
+				Value: 10,
+				Usage: "number of the latest snapshots in history",
+			},
+		},
+	})
+}
+
+type trend struct {
+	Out io.Writer
+}
+
+func (t trend) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	store, err := newSnapshotStore(conf)
+	if err != nil {
+		return err
+	}
+	snapshots, err := store.Load(snapshotKey(conf, c.String("team")))
+	if err != nil {
+		return err
+	}
+
+	if since := c.String("since"); since != "" {
+		sinceTime, err := time.ParseInLocation(metricsDateFormat, since, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date, must be YYYY-MM-DD (%s)", since)
+		}
+		for len(snapshots) > 0 && snapshots[0].Time.Before(sinceTime) {
+			snapshots = snapshots[1:]
+		}
+	}
+
+	if len(snapshots) < 2 {
+		return fmt.Errorf("there are not enough snapshots to compare (snapshots=%d)", len(snapshots))
+	}
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+
+	// output
+	fmt.Fprintf(t.Out, "# Trend for `%s` (%s -> %s, snapshots=%d)\n", last.Key, first.Time.Format(snapshotTimeFormat), last.Time.Format(snapshotTimeFormat), len(snapshots))
+
+	fmt.Fprintln(t.Out, "  * high level issues")
+	fmt.Fprintf(t.Out, "    %d -> %d\n", len(first.HighIssues), len(last.HighIssues))
+	fmt.Fprintf(t.Out, "    new: %s\n", t.numbers(diffInt(last.HighIssues, first.HighIssues)))
+	fmt.Fprintf(t.Out, "    resolved: %s\n", t.numbers(diffInt(first.HighIssues, last.HighIssues)))
+	fmt.Fprintln(t.Out, "")
+
+	fmt.Fprintln(t.Out, "  * assignee load")
+	for _, v := range t.assignees(first, last) {
+		fmt.Fprintf(t.Out, "    - %s: %d -> %d (score=%g -> %g)\n", v,
+			len(first.AssigneeIssues[v]), len(last.AssigneeIssues[v]), first.AssigneeScores[v], last.AssigneeScores[v])
+	}
+	fmt.Fprintln(t.Out, "")
+
+	fmt.Fprintln(t.Out, "  * priority buckets")
+	for _, v := range append(conf.getPriorityLabels(""), noPriorityLabel) {
+		before, after := len(first.PriorityIssues[v]), len(last.PriorityIssues[v])
+		if before == 0 && after == 0 {
+			continue
+		}
+		fmt.Fprintf(t.Out, "    - %s: %d -> %d (%+d)\n", v, before, after, after-before)
+	}
+	fmt.Fprintln(t.Out, "")
+
+	fmt.Fprintln(t.Out, "  * history")
+	history := snapshots
+	if n := c.Int("history"); n > 0 && len(history) > n {
+		history = history[len(history)-n:]
+	}
+	for _, v := range history {
+		fmt.Fprintf(t.Out, "    %s: issues=%d, high=%d\n", v.Time.Format(snapshotTimeFormat), len(v.Issues), len(v.HighIssues))
+	}
+
+	return nil
+}
+
+const snapshotTimeFormat = "2006-01-02 15:04"
+
+func (t trend) numbers(list []int) string {
+	if len(list) == 0 {
+		return "-"
+	}
+	return concatInt(list, ", ")
+}
+
+// assignees returns assignees in the snapshots sorted by the latest score in descending order.
+func (t trend) assignees(first, last issueSnapshot) []string {
+	list := []string{}
+	for k := range last.AssigneeIssues {
+		list = append(list, k)
+	}
+	for k := range first.AssigneeIssues {
+		if _, ok := last.AssigneeIssues[k]; !ok {
+			list = append(list, k)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if last.AssigneeScores[list[i]] != last.AssigneeScores[list[j]] {
+			return last.AssigneeScores[list[i]] > last.AssigneeScores[list[j]]
+		}
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// diffInt returns the numbers in a which are not in b.
+func diffInt(a, b []int) []int {
+	list := []int{}
+	for _, v := range a {
+		if !existInt(b, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  "triage",
+		Usage: "propose labels, assignees and milestones for untriaged issues with rules file",
+		Action: func(c *cli.Context) error {
+			return action(c, &triage{Out: c.App.Writer})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "triage_rules.json",
+				Usage: "you can change triage rules json file",
+			},
+			cli.BoolFlag{
+				Name:  "update, u",
+				Usage: "if this option is set, send update request to github",
+			},
+		},
+	})
+}
+
+type triage struct {
+	Out io.Writer
+}
+
+func (t triage) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	rules, err := t.ReadRules(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	issues, err := issue{}.getAllIssues(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	triageOpes := []triageOpe{}
+	for _, is := range issues {
+		if len(is.Labels) > 0 {
+			continue
+		}
+		var paths []string
+		if is.IsPullRequest() && rules.hasPaths() {
+			paths, err = t.getChangedFiles(client, *conf.User, *conf.Repo, *is.Number)
+			if err != nil {
+				return err
+			}
+		}
+		if ope, ok := rules.apply(is, paths); ok {
+			triageOpes = append(triageOpes, ope)
+		}
+	}
+
+	// output
+	fmt.Fprintf(t.Out, "# Triage for `%s/%s`\n", *conf.User, *conf.Repo)
+	if len(triageOpes) == 0 {
+		fmt.Fprintln(t.Out, "    there are no issues to be triaged")
+		return nil
+	}
+	for _, v := range triageOpes {
+		fmt.Fprintf(t.Out, "  * %d: %s\n", v.Number, v.Title)
+		if len(v.Labels) > 0 {
+			fmt.Fprintf(t.Out, "    labels: %s\n", concatStrWithBracket(v.Labels, ", ", "`"))
+		}
+		if len(v.Assignees) > 0 {
+			fmt.Fprintf(t.Out, "    assignees: %s\n", concatStrWithBracket(v.Assignees, ", ", "`"))
+		}
+		if v.Milestone != "" {
+			fmt.Fprintf(t.Out, "    milestone: `%s`\n", v.Milestone)
+		}
+	}
+	fmt.Fprintln(t.Out, "")
+
+	if !c.Bool("update") {
+		return nil
+	}
+
+	milestones, err := t.getMilestones(client, *conf.User, *conf.Repo)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(t.Out, "  Update in progress...")
+	ctx := context.Background()
+	for _, v := range triageOpes {
+		if len(v.Labels) > 0 {
+			_, _, err = client.Issues.AddLabelsToIssue(ctx, *conf.User, *conf.Repo, v.Number, v.Labels)
+			t.outputUpdResult(v.Number, "add labels", err)
+		}
+		if len(v.Assignees) > 0 {
+			_, _, err = client.Issues.AddAssignees(ctx, *conf.User, *conf.Repo, v.Number, v.Assignees)
+			t.outputUpdResult(v.Number, "add assignees", err)
+		}
+		if v.Milestone != "" {
+			num, ok := milestones[v.Milestone]
+			if !ok {
+				t.outputUpdResult(v.Number, "set milestone", fmt.Errorf("milestone is not found (%s)", v.Milestone))
+				continue
+			}
+			_, _, err = client.Issues.Edit(ctx, *conf.User, *conf.Repo, v.Number, &github.IssueRequest{Milestone: &num})
+			t.outputUpdResult(v.Number, "set milestone", err)
+		}
+	}
+
+	return nil
+}
+
+func (t triage) outputUpdResult(number int, operation string, err error) {
+	if err != nil {
+		fmt.Fprintf(t.Out, "    %d -> %s fail (err=\"%s\")\n", number, operation, err.Error())
+	} else {
+		fmt.Fprintf(t.Out, "    %d -> %s success\n", number, operation)
+	}
+}
+
+type triageRules struct {
+	Rules []struct {
+		Title     string   `json:"title"`
+		Body      string   `json:"body"`
+		Author    string   `json:"author"`
+		Paths     []string `json:"paths"`
+		Labels    []string `json:"labels"`
+		Assignees []string `json:"assignees"`
+		Milestone string   `json:"milestone"`
+
+		titlePtn, bodyPtn *regexp.Regexp
+		pathPtns          []*regexp.Regexp
+	} `json:"rules"`
+}
+
+func (t triage) ReadRules(filename string) (*triageRules, error) {
+
+	rules := &triageRules{}
+
+	jsonStr, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("not found rules file (%s)", filename)
+	}
+
+	err = json.Unmarshal(jsonStr, rules)
+	if err != nil {
+		return nil, fmt.Errorf("something wrong in rules file (%s)", filename)
+	}
+
+	// compile patterns
+	for i := range rules.Rules {
+		r := &rules.Rules[i]
+		if r.Title != "" {
+			if r.titlePtn, err = regexp.Compile(r.Title); err != nil {
+				return nil, fmt.Errorf("invalid regular expression in rules file (%s)", r.Title)
+			}
+		}
+		if r.Body != "" {
+			if r.bodyPtn, err = regexp.Compile(r.Body); err != nil {
+				return nil, fmt.Errorf("invalid regular expression in rules file (%s)", r.Body)
+			}
+		}
+		for _, v := range r.Paths {
+			ptn, err := regexp.Compile(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression in rules file (%s)", v)
+			}
+			r.pathPtns = append(r.pathPtns, ptn)
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *triageRules) hasPaths() bool {
+	for _, v := range r.Rules {
+		if len(v.Paths) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the proposal for the issue.
+// All conditions of a rule must be satisfied, and proposals of the matched rules are merged.
+// ok is false if the proposal doesn't change the issue, even if some rules match.
+func (r *triageRules) apply(is *github.Issue, paths []string) (ope triageOpe, ok bool) {
+
+	ope = triageOpe{Number: *is.Number, Title: is.GetTitle()}
+	current := []string{}
+	for _, v := range is.Labels {
+		current = append(current, v.GetName())
+	}
+
+	for _, v := range r.Rules {
+		if v.titlePtn != nil && !v.titlePtn.MatchString(is.GetTitle()) {
+			continue
+		}
+		if v.bodyPtn != nil && !v.bodyPtn.MatchString(is.GetBody()) {
+			continue
+		}
+		if v.Author != "" && v.Author != is.GetUser().GetLogin() {
+			continue
+		}
+		if len(v.pathPtns) > 0 && !matchAnyPath(v.pathPtns, paths) {
+			continue
+		}
+		for _, l := range v.Labels {
+			if !existStr(ope.Labels, l) && !existStr(current, l) {
+				ope.Labels = append(ope.Labels, l)
+			}
+		}
+		if len(is.Assignees) == 0 {
+			for _, a := range v.Assignees {
+				if !existStr(ope.Assignees, a) {
+					ope.Assignees = append(ope.Assignees, a)
+				}
+			}
+		}
+		if is.Milestone == nil && ope.Milestone == "" {
+			ope.Milestone = v.Milestone
+		}
+	}
+
+	return ope, len(ope.Labels) > 0 || len(ope.Assignees) > 0 || ope.Milestone != ""
+}
+
+func matchAnyPath(ptns []*regexp.Regexp, paths []string) bool {
+	for _, ptn := range ptns {
+		for _, p := range paths {
+			if ptn.MatchString(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t triage) getChangedFiles(client *github.Client, user, repo string, number int) ([]string, error) {
+
+	opt := &github.ListOptions{
+		Page:    1,
+		PerPage: 100,
+	}
+
+	var paths []string
+	for {
+		files, resp, err := client.PullRequests.ListFiles(context.Background(), user, repo, number, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			paths = append(paths, *f.Filename)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return paths, nil
+}
+
+// getMilestones returns a map of milestone title and number.
+func (t triage) getMilestones(client *github.Client, user, repo string) (map[string]int, error) {
+
+	opt := &github.MilestoneListOptions{
+		State: "open",
+		ListOptions: github.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+
+	milestones := make(map[string]int)
+	for {
+		list, resp, err := client.Issues.ListMilestones(context.Background(), user, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			milestones[*m.Title] = *m.Number
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return milestones, nil
+}
+
+type triageOpe struct {
+	Number            int
+	Title, Milestone  string
+	Labels, Assignees []string
+}
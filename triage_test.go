@@ -0,0 +1,154 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestTriageReadRules(t *testing.T) {
+
+	if _, err := (triage{}).ReadRules("testdata/triage_rules.json"); err != nil {
+		t.Errorf("ReadRules returns error: %s", err)
+	}
+	if _, err := (triage{}).ReadRules("testdata/undefined.json"); err == nil {
+		t.Error("ReadRules doesn't return error for undefined file")
+	}
+}
+
+func TestTriageApply(t *testing.T) {
+
+	rules, err := triage{}.ReadRules("testdata/triage_rules.json")
+	if err != nil {
+		t.Fatalf("ReadRules returns error: %s", err)
+	}
+	if !rules.hasPaths() {
+		t.Error("hasPaths is false for rules with paths")
+	}
+
+	newIssue := func(title, body, author string, assignees []string, milestone bool) *github.Issue {
+		is := newTestIssue(1, nil, assignees)
+		is.Title = github.String(title)
+		is.Body = github.String(body)
+		is.User = &github.User{Login: github.String(author)}
+		if milestone {
+			is.Milestone = &github.Milestone{Title: github.String("v0.9")}
+		}
+		return is
+	}
+	withLabels := func(is *github.Issue, labels ...string) *github.Issue {
+		for _, v := range labels {
+			is.Labels = append(is.Labels, github.Label{Name: github.String(v)})
+		}
+		return is
+	}
+
+	cases := []struct {
+		name     string
+		issue    *github.Issue
+		paths    []string
+		matched  bool
+		expected triageOpe
+	}{
+		{
+			name:    "no rules match",
+			issue:   newIssue("Improve docs", "", "octocat", nil, false),
+			matched: false,
+		},
+		{
+			name:     "title",
+			issue:    newIssue("App crashes on save", "", "octocat", nil, false),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"bug"}},
+		},
+		{
+			// proposals of the matched rules are merged without duplicates
+			name:     "several rules",
+			issue:    newIssue("URGENT: error on login", "", "octocat", nil, false),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"bug", "urgent"}, Assignees: []string{"member-a"}},
+		},
+		{
+			name:     "assigned issue",
+			issue:    newIssue("URGENT: login", "", "octocat", []string{"bob"}, false),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"urgent"}},
+		},
+		{
+			// the rule matches, but the proposal doesn't change the assigned issue
+			name:    "no changes for assigned issue",
+			issue:   newIssue("docs: fix typo", "", "octocat", []string{"bob"}, false),
+			matched: false,
+		},
+		{
+			name:     "unassigned issue",
+			issue:    newIssue("docs: fix typo", "", "octocat", nil, false),
+			matched:  true,
+			expected: triageOpe{Assignees: []string{"member-b"}},
+		},
+		{
+			name:    "labels already attached",
+			issue:   withLabels(newIssue("Crash on save", "", "octocat", nil, false), "bug"),
+			matched: false,
+		},
+		{
+			name:     "some labels already attached",
+			issue:    withLabels(newIssue("URGENT: crash on save", "", "octocat", nil, false), "bug"),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"urgent"}, Assignees: []string{"member-a"}},
+		},
+		{
+			name:     "body",
+			issue:    newIssue("Login", "Steps to reproduce:\n1. open", "octocat", nil, false),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"bug"}},
+		},
+		{
+			name:     "paths",
+			issue:    newIssue("Update styles", "", "octocat", nil, false),
+			paths:    []string{"README.md", "web/style.css"},
+			matched:  true,
+			expected: triageOpe{Labels: []string{"area/frontend"}, Assignees: []string{"member-c"}},
+		},
+		{
+			name:    "paths don't match",
+			issue:   newIssue("Update styles", "", "octocat", nil, false),
+			paths:   []string{"api/web/handler.go"},
+			matched: false,
+		},
+		{
+			// all conditions of the rule must be satisfied
+			name:     "author and title",
+			issue:    newIssue("Bump yaml from 2.0 to 3.0", "", "dependabot[bot]", nil, false),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"minor"}, Milestone: "v1.0"},
+		},
+		{
+			name:    "author only",
+			issue:   newIssue("Update", "", "dependabot[bot]", nil, false),
+			matched: false,
+		},
+		{
+			name:     "issue with milestone",
+			issue:    newIssue("Bump yaml", "", "dependabot[bot]", nil, true),
+			matched:  true,
+			expected: triageOpe{Labels: []string{"minor"}},
+		},
+	}
+
+	for _, c := range cases {
+		ope, matched := rules.apply(c.issue, c.paths)
+		if matched != c.matched {
+			t.Errorf("%s: matched is %t, expected %t", c.name, matched, c.matched)
+			continue
+		}
+		if !matched {
+			continue
+		}
+		c.expected.Number, c.expected.Title = c.issue.GetNumber(), c.issue.GetTitle()
+		if !reflect.DeepEqual(ope, c.expected) {
+			t.Errorf("%s: proposal is %+v, expected %+v", c.name, ope, c.expected)
+		}
+	}
+}
@@ -1,10 +1,34 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
+// writeFileAtomic writes data to a temporary file in the same directory and renames it to filename,
+// so filename is never left half-written.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
 func nvl(str string) string {
 	if str == "" {
 		return "-"
@@ -30,6 +54,16 @@ func existStr(list []string, str string) bool {
 	return false
 }
 
+func removeStr(list []string, str string) []string {
+	result := []string{}
+	for _, v := range list {
+		if v != str {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 func existStrs(list []string, strs []string) bool {
 	for _, str := range strs {
 		if existStr(list, str) {
@@ -70,11 +104,3 @@ func concatStrWith2Brackets(list []string, delimiter, bracketFront, bracketBack
 	}
 	return strings.TrimRight(str, delimiter)
 }
-
-func space(cnt int) string {
-	str := ""
-	for i := 0; i < cnt; i++ {
-		str += " "
-	}
-	return str
-}
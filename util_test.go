@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "githubmgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "out.html")
+	for _, v := range []string{"first", "second"} {
+		if err := writeFileAtomic(filename, []byte(v)); err != nil {
+			t.Fatalf("writeFileAtomic returns error: %s", err)
+		}
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(src) != v {
+			t.Errorf("content is %q, expected %q", src, v)
+		}
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("permission is %s, expected %s", info.Mode().Perm(), os.FileMode(0644))
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("temporary files are left (files=%d)", len(files))
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, "undefined", "out.html"), []byte("x")); err == nil {
+		t.Error("writeFileAtomic doesn't return error for undefined directory")
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/urfave/cli"
+)
+
+const (
+	webhookCmdName      = "webhook"
+	webhookReadTimeout  = 10 * time.Second
+	webhookWriteTimeout = 60 * time.Second
+)
+
+func init() {
+	cmdList = append(cmdList, cli.Command{
+		Name:  webhookCmdName,
+		Usage: "receive webhook events and enforce label settings in real time",
+		Action: func(c *cli.Context) error {
+			return action(c, &webhook{
+				Out: c.App.Writer,
+				Log: log.New(os.Stderr, "", log.LstdFlags),
+			})
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Value: "label_settings.json",
+				Usage: "you can change label setting json file",
+			},
+			cli.StringFlag{
+				Name:  "addr, a",
+				Value: ":8080",
+				Usage: "address to listen on",
+			},
+			cli.StringFlag{
+				Name:   "secret, s",
+				Value:  "",
+				Usage:  "secret of the webhook to validate signatures",
+				EnvVar: "GITHUBMGR_WEBHOOK_SECRET",
+			},
+		},
+	})
+}
+
+type webhook struct {
+	Out io.Writer
+	Log *log.Logger
+}
+
+func (w webhook) Run(c *cli.Context, conf *config, client *github.Client) error {
+
+	setting, err := label{}.ReadSettings(c.String("file"), client)
+	if err != nil {
+		return err
+	}
+
+	secret := c.String("secret")
+	if secret == "" {
+		return errors.New("webhook secret is mandatory")
+	}
+
+	h := &webhookHandler{
+		Log:     w.Log,
+		Secret:  []byte(secret),
+		Client:  client,
+		User:    *conf.User,
+		Repo:    *conf.Repo,
+		Setting: setting,
+	}
+
+	// events caused by the webhook itself are skipped not to react to its own operations
+	if user, _, err := client.Users.Get(context.Background(), ""); err != nil {
+		w.Log.Printf("unable to get the user of the token, events by itself are not skipped (err=\"%s\")", err.Error())
+	} else {
+		h.Login = user.GetLogin()
+	}
+
+	fmt.Fprintf(w.Out, "# Webhook for `%s/%s` (addr=%s)\n", *conf.User, *conf.Repo, c.String("addr"))
+	server := &http.Server{
+		Addr:         c.String("addr"),
+		Handler:      h,
+		ReadTimeout:  webhookReadTimeout,
+		WriteTimeout: webhookWriteTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+type webhookHandler struct {
+	Log        *log.Logger
+	Secret     []byte
+	Client     *github.Client
+	User, Repo string
+	Setting    *labelSetting
+	// Login is the user of the token
+	Login string
+}
+
+func (h *webhookHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+
+	payload, err := github.ValidatePayload(r, h.Secret)
+	if err != nil {
+		h.Log.Printf("invalid payload (err=\"%s\")", err.Error())
+		http.Error(rw, "invalid payload", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		h.Log.Printf("unsupported event (err=\"%s\")", err.Error())
+		http.Error(rw, "unsupported event", http.StatusBadRequest)
+		return
+	}
+
+	if h.isSelf(payload) {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch e := event.(type) {
+	case *github.LabelEvent:
+		if h.isTargetRepo(e.GetRepo()) {
+			h.handleLabelEvent(e, payload)
+		}
+	case *github.IssuesEvent:
+		number := e.GetIssue().GetNumber()
+		if h.isTargetRepo(e.GetRepo()) && number != 0 && (e.GetAction() == "opened" || e.GetAction() == "labeled") {
+			names := []string{}
+			for _, v := range e.GetIssue().Labels {
+				names = append(names, v.GetName())
+			}
+			h.enforceIssueLabels(number, names)
+		}
+	case *github.PullRequestEvent:
+		number := e.GetNumber()
+		if h.isTargetRepo(e.GetRepo()) && number != 0 && (e.GetAction() == "opened" || e.GetAction() == "labeled") {
+			labels, _, err := h.Client.Issues.ListLabelsByIssue(context.Background(), h.User, h.Repo, number, nil)
+			if err != nil {
+				h.Log.Printf("%d: list labels fail (err=\"%s\")", number, err.Error())
+				break
+			}
+			names := []string{}
+			for _, v := range labels {
+				names = append(names, v.GetName())
+			}
+			h.enforceIssueLabels(number, names)
+		}
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (h *webhookHandler) isTargetRepo(repo *github.Repository) bool {
+	return repo.GetOwner().GetLogin() == h.User && repo.GetName() == h.Repo
+}
+
+// isSelf returns true if the event is caused by the user of the token.
+// LabelEvent doesn't have Sender in v15.0.0, so read it from payload.
+func (h *webhookHandler) isSelf(payload []byte) bool {
+	if h.Login == "" {
+		return false
+	}
+	var sender struct {
+		Sender struct {
+			Login string `json:"login"`
+		} `json:"sender"`
+	}
+	if err := json.Unmarshal(payload, &sender); err != nil {
+		return false
+	}
+	return sender.Sender.Login == h.Login
+}
+
+// isManaged returns true if the label is in the settings, and neither replaced nor ignored.
+func (h *webhookHandler) isManaged(name string) bool {
+	item, ok := h.Setting.LabelMap[name]
+	return ok && item.ReplaceTo == "" && !item.IsIgnore && !h.Setting.isIgnored(name)
+}
+
+// handleLabelEvent rejects labels not in the settings.
+// A created label is deleted and a renamed label is renamed back if it was managed.
+// A renamed label which was not managed is left alone, because it wasn't created by the event.
+func (h *webhookHandler) handleLabelEvent(e *github.LabelEvent, payload []byte) {
+
+	name := e.GetLabel().GetName()
+	if name == "" {
+		return
+	}
+	ctx := context.Background()
+
+	if item, ok := h.Setting.LabelMap[name]; ok && item.ReplaceTo == "" && !item.IsIgnore {
+		if item.Color != "" && item.Color != e.GetLabel().GetColor() {
+			_, _, err := h.Client.Issues.EditLabel(ctx, h.User, h.Repo, name, &github.Label{Name: &name, Color: &item.Color})
+			h.logResult(fmt.Sprintf("`%s` -> %s", name, opeUpd), err)
+		}
+		return
+	}
+	if h.Setting.isIgnored(name) {
+		return
+	}
+
+	switch e.GetAction() {
+	case "created":
+		_, err := h.Client.Issues.DeleteLabel(ctx, h.User, h.Repo, name)
+		h.logResult(fmt.Sprintf("`%s` -> %s", name, opeDel), err)
+	case "edited":
+		// github.EditChange doesn't have Name in v15.0.0, so read it from payload
+		var changes struct {
+			Changes struct {
+				Name *struct {
+					From string `json:"from"`
+				} `json:"name"`
+			} `json:"changes"`
+		}
+		if err := json.Unmarshal(payload, &changes); err != nil || changes.Changes.Name == nil {
+			return
+		}
+		from := changes.Changes.Name.From
+		if !h.isManaged(from) {
+			h.Log.Printf("`%s` -> renamed from `%s` which is not managed, left alone", name, from)
+			return
+		}
+		_, _, err := h.Client.Issues.EditLabel(ctx, h.User, h.Repo, name, &github.Label{Name: &from, Color: e.GetLabel().Color})
+		h.logResult(fmt.Sprintf("`%s` -> rename to `%s`", name, from), err)
+	}
+}
+
+// enforceIssueLabels replaces or removes labels of the issue which are not in the settings.
+func (h *webhookHandler) enforceIssueLabels(number int, names []string) {
+
+	ctx := context.Background()
+
+	for _, name := range names {
+		item, ok := h.Setting.LabelMap[name]
+		if ok && item.ReplaceTo == "" {
+			continue
+		}
+		if !ok && h.Setting.isIgnored(name) {
+			continue
+		}
+		if ok && item.ReplaceTo != "" {
+			_, _, err := h.Client.Issues.AddLabelsToIssue(ctx, h.User, h.Repo, number, []string{item.ReplaceTo})
+			h.logResult(fmt.Sprintf("`%s` -> %s (issue num = %d)", item.ReplaceTo, opeIss, number), err)
+			if err != nil {
+				continue
+			}
+		}
+		_, err := h.Client.Issues.RemoveLabelForIssue(ctx, h.User, h.Repo, number, name)
+		h.logResult(fmt.Sprintf("`%s` -> remove (issue num = %d)", name, number), err)
+	}
+}
+
+func (h *webhookHandler) logResult(ope string, err error) {
+	if err != nil {
+		h.Log.Printf("%s fail (err=\"%s\")", ope, err.Error())
+	} else {
+		h.Log.Printf("%s success", ope)
+	}
+}
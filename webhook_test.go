@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+const testWebhookSecret = "secret"
+
+// newTestWebhook returns the handler with a github api server which records requests as "METHOD path body".
+func newTestWebhook(t *testing.T) (*webhookHandler, *[]string, func()) {
+
+	requests := &[]string{}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/labels") && strings.Contains(r.URL.Path, "/issues/") {
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`[{"name": "bug"}, {"name": "wontfix"}, {"name": "duplicate"}]`))
+			} else {
+				w.Write([]byte("[]"))
+			}
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(api.URL + "/")
+
+	setting := &labelSetting{
+		Ignore: []string{"area/.*"},
+		LabelMap: map[string]labelItem{
+			"bug":     {Color: "d73a4a"},
+			"pending": {Color: "2d86ee"},
+			"wontfix": {ReplaceTo: "pending"},
+			"area/.*": {IsIgnore: true},
+		},
+	}
+
+	h := &webhookHandler{
+		Log:     log.New(ioutil.Discard, "", 0),
+		Secret:  []byte(testWebhookSecret),
+		Client:  client,
+		User:    "o",
+		Repo:    "r",
+		Setting: setting,
+		Login:   "githubmgr-bot",
+	}
+	return h, requests, api.Close
+}
+
+// postWebhook posts the payload signed with the secret to the handler and returns the status code.
+func postWebhook(t *testing.T, h http.Handler, event, payload, secret string) int {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Github-Event", event)
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+const testWebhookRepo = `"repository": {"name": "r", "owner": {"login": "o"}}`
+
+func TestWebhookInvalidSignature(t *testing.T) {
+
+	h, requests, closeAPI := newTestWebhook(t)
+	defer closeAPI()
+
+	payload := `{"action": "created", "label": {"name": "new"}, ` + testWebhookRepo + `}`
+	if status := postWebhook(t, h, "label", payload, "wrong secret"); status != http.StatusUnauthorized {
+		t.Errorf("status is %d, expected %d", status, http.StatusUnauthorized)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("requests are sent for invalid signature: %v", *requests)
+	}
+}
+
+func TestWebhookPartialPayload(t *testing.T) {
+
+	h, requests, closeAPI := newTestWebhook(t)
+	defer closeAPI()
+
+	cases := []struct {
+		event, payload string
+	}{
+		{"label", `{}`},
+		{"label", `{"action": "created", ` + testWebhookRepo + `}`},
+		{"label", `{"action": "created", "label": {"name": "new"}}`},
+		{"label", `{"action": "created", "label": {"name": "new"}, "repository": {"name": "r"}}`},
+		{"issues", `{"action": "opened", ` + testWebhookRepo + `}`},
+		{"issues", `{"issue": {"number": 1, "labels": [{"name": "duplicate"}]}, ` + testWebhookRepo + `}`},
+		{"pull_request", `{"action": "opened", ` + testWebhookRepo + `}`},
+	}
+
+	for _, c := range cases {
+		if status := postWebhook(t, h, c.event, c.payload, testWebhookSecret); status != http.StatusOK {
+			t.Errorf("%s %s: status is %d, expected %d", c.event, c.payload, status, http.StatusOK)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("requests are sent for partial payloads: %v", *requests)
+	}
+}
+
+func TestWebhookHandleLabelEvent(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		payload  string
+		expected []string
+	}{
+		{
+			name:     "created label not in settings",
+			payload:  `{"action": "created", "label": {"name": "new", "color": "ffffff"}}`,
+			expected: []string{"DELETE /repos/o/r/labels/new"},
+		},
+		{
+			name:     "created replaced label",
+			payload:  `{"action": "created", "label": {"name": "wontfix", "color": "ffffff"}}`,
+			expected: []string{"DELETE /repos/o/r/labels/wontfix"},
+		},
+		{
+			name:     "created ignored label",
+			payload:  `{"action": "created", "label": {"name": "area/web", "color": "ffffff"}}`,
+			expected: []string{},
+		},
+		{
+			name:     "managed label with another color",
+			payload:  `{"action": "edited", "label": {"name": "bug", "color": "ffffff"}}`,
+			expected: []string{`PATCH /repos/o/r/labels/bug {"name":"bug","color":"d73a4a"}`},
+		},
+		{
+			name:     "managed label with the same color",
+			payload:  `{"action": "created", "label": {"name": "bug", "color": "d73a4a"}}`,
+			expected: []string{},
+		},
+		{
+			name:     "renamed from managed label",
+			payload:  `{"action": "edited", "label": {"name": "defect", "color": "d73a4a"}, "changes": {"name": {"from": "bug"}}}`,
+			expected: []string{`PATCH /repos/o/r/labels/defect {"name":"bug","color":"d73a4a"}`},
+		},
+		{
+			name:     "renamed from label not in settings",
+			payload:  `{"action": "edited", "label": {"name": "defect", "color": "d73a4a"}, "changes": {"name": {"from": "other"}}}`,
+			expected: []string{},
+		},
+		{
+			name:     "renamed from replaced label",
+			payload:  `{"action": "edited", "label": {"name": "defect", "color": "d73a4a"}, "changes": {"name": {"from": "wontfix"}}}`,
+			expected: []string{},
+		},
+		{
+			name:     "edited without rename",
+			payload:  `{"action": "edited", "label": {"name": "defect", "color": "d73a4a"}, "changes": {"color": {"from": "ffffff"}}}`,
+			expected: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		h, requests, closeAPI := newTestWebhook(t)
+
+		payload := c.payload[:len(c.payload)-1] + ", " + testWebhookRepo + "}"
+		if status := postWebhook(t, h, "label", payload, testWebhookSecret); status != http.StatusOK {
+			t.Errorf("%s: status is %d, expected %d", c.name, status, http.StatusOK)
+		}
+		if !reflect.DeepEqual(*requests, c.expected) {
+			t.Errorf("%s: requests are %v, expected %v", c.name, *requests, c.expected)
+		}
+
+		// events caused by the webhook itself are skipped
+		*requests = []string{}
+		payload = c.payload[:len(c.payload)-1] + `, "sender": {"login": "githubmgr-bot"}, ` + testWebhookRepo + "}"
+		postWebhook(t, h, "label", payload, testWebhookSecret)
+		if len(*requests) != 0 {
+			t.Errorf("%s: requests are sent for its own event: %v", c.name, *requests)
+		}
+
+		closeAPI()
+	}
+}
+
+func TestWebhookEnforceIssueLabels(t *testing.T) {
+
+	h, requests, closeAPI := newTestWebhook(t)
+	defer closeAPI()
+
+	payload := `{"action": "labeled", "issue": {"number": 3, "labels": [{"name": "bug"}, {"name": "wontfix"}, {"name": "area/web"}, {"name": "duplicate"}]}, ` + testWebhookRepo + `}`
+	postWebhook(t, h, "issues", payload, testWebhookSecret)
+	expected := []string{
+		`POST /repos/o/r/issues/3/labels ["pending"]`,
+		"DELETE /repos/o/r/issues/3/labels/wontfix",
+		"DELETE /repos/o/r/issues/3/labels/duplicate",
+	}
+	if !reflect.DeepEqual(*requests, expected) {
+		t.Errorf("requests for issue are %v, expected %v", *requests, expected)
+	}
+
+	*requests = []string{}
+	postWebhook(t, h, "pull_request", `{"action": "opened", "number": 7, `+testWebhookRepo+`}`, testWebhookSecret)
+	expected = []string{
+		"GET /repos/o/r/issues/7/labels",
+		`POST /repos/o/r/issues/7/labels ["pending"]`,
+		"DELETE /repos/o/r/issues/7/labels/wontfix",
+		"DELETE /repos/o/r/issues/7/labels/duplicate",
+	}
+	if !reflect.DeepEqual(*requests, expected) {
+		t.Errorf("requests for pull request are %v, expected %v", *requests, expected)
+	}
+}